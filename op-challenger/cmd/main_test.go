@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
 	"testing"
 	"time"
 
@@ -188,6 +189,37 @@ func TestMaxConcurrency(t *testing.T) {
 	})
 }
 
+func TestMaxPreimageUploads(t *testing.T) {
+	t.Run("UsesDefault", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet))
+		require.Equal(t, uint(runtime.NumCPU()), cfg.MaxPreimageUploads)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--max-preimage-uploads", "3"))
+		require.Equal(t, uint(3), cfg.MaxPreimageUploads)
+	})
+
+	t.Run("Zero", func(t *testing.T) {
+		verifyArgsInvalid(
+			t,
+			"max-preimage-uploads must not be 0",
+			addRequiredArgs(config.TraceTypeAlphabet, "--max-preimage-uploads", "0"))
+	})
+}
+
+func TestPreimageTxInterval(t *testing.T) {
+	t.Run("UsesDefault", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet))
+		require.Zero(t, cfg.PreimageTxInterval)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--preimage-tx-interval", "5s"))
+		require.Equal(t, 5*time.Second, cfg.PreimageTxInterval)
+	})
+}
+
 func TestPollInterval(t *testing.T) {
 	t.Run("UsesDefault", func(t *testing.T) {
 		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeCannon))
@@ -19,6 +19,7 @@ var (
 	ErrMissingTraceType              = errors.New("no supported trace types specified")
 	ErrMissingDatadir                = errors.New("missing datadir")
 	ErrMaxConcurrencyZero            = errors.New("max concurrency must not be 0")
+	ErrMaxPreimageUploadsZero        = errors.New("max concurrent preimage uploads must not be 0")
 	ErrMissingCannonL2               = errors.New("missing cannon L2")
 	ErrMissingCannonBin              = errors.New("missing cannon bin")
 	ErrMissingCannonServer           = errors.New("missing cannon server")
@@ -106,6 +107,9 @@ type Config struct {
 	MaxConcurrency     uint             // Maximum number of threads to use when progressing games
 	PollInterval       time.Duration    // Polling interval for latest-block subscription when using an HTTP RPC provider
 
+	MaxPreimageUploads uint          // Maximum number of large preimages to upload at once, across all games
+	PreimageTxInterval time.Duration // Minimum time between sending large preimage upload transactions
+
 	TraceTypes []TraceType // Type of traces supported
 
 	// Specific to the output cannon trace type
@@ -138,6 +142,7 @@ func NewConfig(
 		GameFactoryAddress: gameFactoryAddress,
 		MaxConcurrency:     uint(runtime.NumCPU()),
 		PollInterval:       DefaultPollInterval,
+		MaxPreimageUploads: uint(runtime.NumCPU()),
 
 		TraceTypes: supportedTraceTypes,
 
@@ -176,6 +181,9 @@ func (c Config) Check() error {
 	if c.MaxConcurrency == 0 {
 		return ErrMaxConcurrencyZero
 	}
+	if c.MaxPreimageUploads == 0 {
+		return ErrMaxPreimageUploadsZero
+	}
 	if c.TraceTypeEnabled(TraceTypeCannon) {
 		if c.CannonBin == "" {
 			return ErrMissingCannonBin
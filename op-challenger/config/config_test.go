@@ -109,6 +109,19 @@ func TestMaxConcurrency(t *testing.T) {
 	})
 }
 
+func TestMaxPreimageUploads(t *testing.T) {
+	t.Run("Required", func(t *testing.T) {
+		config := validConfig(TraceTypeAlphabet)
+		config.MaxPreimageUploads = 0
+		require.ErrorIs(t, config.Check(), ErrMaxPreimageUploadsZero)
+	})
+
+	t.Run("DefaultToNumberOfCPUs", func(t *testing.T) {
+		config := validConfig(TraceTypeAlphabet)
+		require.EqualValues(t, runtime.NumCPU(), config.MaxPreimageUploads)
+	})
+}
+
 func TestHttpPollInterval(t *testing.T) {
 	t.Run("Default", func(t *testing.T) {
 		config := validConfig(TraceTypeAlphabet)
@@ -69,6 +69,17 @@ var (
 		EnvVars: prefixEnvVars("HTTP_POLL_INTERVAL"),
 		Value:   config.DefaultPollInterval,
 	}
+	MaxPreimageUploadsFlag = &cli.UintFlag{
+		Name:    "max-preimage-uploads",
+		Usage:   "Maximum number of large preimages to upload at once, across all games",
+		EnvVars: prefixEnvVars("MAX_PREIMAGE_UPLOADS"),
+		Value:   uint(runtime.NumCPU()),
+	}
+	PreimageTxIntervalFlag = &cli.DurationFlag{
+		Name:    "preimage-tx-interval",
+		Usage:   "Minimum time between sending large preimage upload transactions. Zero sends them as soon as they are ready.",
+		EnvVars: prefixEnvVars("PREIMAGE_TX_INTERVAL"),
+	}
 	RollupRpcFlag = &cli.StringFlag{
 		Name:    "rollup-rpc",
 		Usage:   "HTTP provider URL for the rollup node",
@@ -144,6 +155,8 @@ var optionalFlags = []cli.Flag{
 	TraceTypeFlag,
 	MaxConcurrencyFlag,
 	HTTPPollInterval,
+	MaxPreimageUploadsFlag,
+	PreimageTxIntervalFlag,
 	RollupRpcFlag,
 	GameAllowlistFlag,
 	CannonNetworkFlag,
@@ -268,6 +281,10 @@ func NewConfigFromCLI(ctx *cli.Context) (*config.Config, error) {
 	if maxConcurrency == 0 {
 		return nil, fmt.Errorf("%v must not be 0", MaxConcurrencyFlag.Name)
 	}
+	maxPreimageUploads := ctx.Uint(MaxPreimageUploadsFlag.Name)
+	if maxPreimageUploads == 0 {
+		return nil, fmt.Errorf("%v must not be 0", MaxPreimageUploadsFlag.Name)
+	}
 	return &config.Config{
 		// Required Flags
 		L1EthRpc:               ctx.String(L1EthRpcFlag.Name),
@@ -277,6 +294,8 @@ func NewConfigFromCLI(ctx *cli.Context) (*config.Config, error) {
 		GameWindow:             ctx.Duration(GameWindowFlag.Name),
 		MaxConcurrency:         maxConcurrency,
 		PollInterval:           ctx.Duration(HTTPPollInterval.Name),
+		MaxPreimageUploads:     maxPreimageUploads,
+		PreimageTxInterval:     ctx.Duration(PreimageTxIntervalFlag.Name),
 		RollupRpc:              ctx.String(RollupRpcFlag.Name),
 		CannonNetwork:          ctx.String(CannonNetworkFlag.Name),
 		CannonRollupConfigPath: ctx.String(CannonRollupConfigFlag.Name),
@@ -133,6 +133,15 @@ func (f *FaultDisputeGameContract) GetOracle(ctx context.Context) (*PreimageOrac
 	return vm.Oracle(ctx)
 }
 
+// GetOracleAddr returns the address of the preimage oracle used by the game's VM.
+func (f *FaultDisputeGameContract) GetOracleAddr(ctx context.Context) (common.Address, error) {
+	vm, err := f.vm(ctx)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return vm.OracleAddr(ctx)
+}
+
 func (f *FaultDisputeGameContract) GetGameDuration(ctx context.Context) (uint64, error) {
 	result, err := f.multiCaller.SingleCall(ctx, batching.BlockLatest, f.contract.Call(methodGameDuration))
 	if err != nil {
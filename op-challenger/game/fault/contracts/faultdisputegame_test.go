@@ -113,6 +113,10 @@ func TestGetOracleAddr(t *testing.T) {
 	actual, err := game.GetOracle(context.Background())
 	require.NoError(t, err)
 	require.Equal(t, oracleAddr, actual.Addr())
+
+	addr, err := game.GetOracleAddr(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, oracleAddr, addr)
 }
 
 func TestGetClaim(t *testing.T) {
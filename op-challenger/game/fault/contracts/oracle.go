@@ -1,37 +1,235 @@
 package contracts
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"math/big"
+	"slices"
+	"sync"
 
 	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
 	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/types"
+	"github.com/ethereum-optimism/optimism/op-challenger/game/keccak/matrix"
+	"github.com/ethereum-optimism/optimism/op-challenger/game/keccak/merkle"
+	gameTypes "github.com/ethereum-optimism/optimism/op-challenger/game/types"
 	"github.com/ethereum-optimism/optimism/op-service/sources/batching"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 	"github.com/ethereum/go-ethereum/common"
 )
 
 const (
+	methodAggregate3 = "aggregate3"
+
+	methodInitLPP                   = "initLPP"
+	methodAddLeavesLPP              = "addLeavesLPP"
 	methodLoadKeccak256PreimagePart = "loadKeccak256PreimagePart"
+	methodProposalMetadata          = "proposalMetadata"
+	methodSqueezeLPP                = "squeezeLPP"
+	methodChallengePeriod           = "challengePeriod"
+	methodProposalCount             = "proposalCount"
+	methodProposals                 = "proposals"
+	methodGetTreeRootLPP            = "getTreeRootLPP"
+	methodProposalBlocksLen         = "proposalBlocksLen"
+	methodProposalBlocks            = "proposalBlocks"
+	methodChallengeLPP              = "challengeLPP"
+	methodChallengeFirstLPP         = "challengeFirstLPP"
+	methodMaxLeafCount              = "MAX_LEAF_COUNT"
+	methodPreimagePartOk            = "preimagePartOk"
+)
+
+// MaxTxCalldataSize is the maximum calldata used for large preimage leaves in a single transaction.
+// It is kept below the 128KB transaction size limit to leave room for the rest of the transaction.
+const MaxTxCalldataSize = 120_000
+
+// addLeavesCalldataOverhead is the calldata of an addLeavesLPP call other than the leaf inputs and commitments.
+// It is made up of the 4 byte selector, four head words, the length words of the input and commitments and up to
+// 31 bytes padding the input to a whole word.
+const addLeavesCalldataOverhead = 4 + 6*common.HashLength + common.HashLength - 1
+
+// DefaultMaxLeavesPerTx is the number of leaves that fit within MaxTxCalldataSize.
+// Each leaf contributes its matrix.LeafSize byte input and a 32 byte state commitment to the calldata.
+const DefaultMaxLeavesPerTx = (MaxTxCalldataSize - addLeavesCalldataOverhead) / (matrix.LeafSize + common.HashLength)
+
+// MaxDirectPreimageSize is the largest preimage, including the 8 byte size prefix, that is loaded into the oracle
+// with a single loadKeccak256PreimagePart call. Larger preimages must use the large preimage proposal process.
+// TODO(client-pod#467): determine the correct size threshold to toggle between
+//
+//	the direct and large preimage uploaders.
+const MaxDirectPreimageSize = matrix.LeafSize * 128
+
+var (
+	ErrPreimageTooLargeForDirectLoad = errors.New("preimage too large to load directly, use the large preimage proposal process")
+	ErrInvalidAddLeavesCall          = errors.New("tx is not a valid addLeaves call")
+	ErrLeavesNotContiguous           = errors.New("leaves are not contiguous")
+	ErrMalformedProposalMetadata     = errors.New("malformed proposal metadata result")
+	ErrPartOffsetOOB                 = errors.New("preimage part cannot be included in a non-final leaf batch")
+	ErrMissingLeafIndex              = errors.New("leaf index missing")
 )
 
+// NeedsLargePreimage returns true if the preimage is too large to be loaded into the oracle in a single call and
+// must be uploaded using the large preimage proposal process instead.
+func NeedsLargePreimage(data *types.PreimageOracleData) bool {
+	return len(data.OracleData) > MaxDirectPreimageSize
+}
+
 // PreimageOracleContract is a binding that works with contracts implementing the IPreimageOracle interface
 type PreimageOracleContract struct {
 	addr        common.Address
 	multiCaller *batching.MultiCaller
 	contract    *batching.BoundContract
+	multicall   *batching.BoundContract
+
+	// challengePeriod is immutable once the contract is deployed so is cached after the first successful read.
+	challengePeriodLock sync.Mutex
+	challengePeriod     *uint64
+
+	// maxProposalSize is derived from the immutable max leaf count so is also cached after the first successful read.
+	maxProposalSizeLock sync.Mutex
+	maxProposalSize     *uint64
+}
+
+// Leaf is the keccak state matrix added to the large preimage merkle tree.
+type Leaf = matrix.Leaf
+
+func toPreimageOracleLeaf(l Leaf) bindings.PreimageOracleLeaf {
+	padded := l.PaddedInput()
+	return bindings.PreimageOracleLeaf{
+		Input:           padded[:],
+		Index:           l.Index,
+		StateCommitment: l.StateCommitment,
+	}
+}
+
+// LargePreimageIdentifier identifies a large preimage proposal by the claimant that created it and its uuid.
+type LargePreimageIdentifier struct {
+	Claimant common.Address
+	UUID     *big.Int
+}
+
+// LargePreimageKey is a comparable form of LargePreimageIdentifier, suitable for use as a map key.
+type LargePreimageKey struct {
+	Claimant common.Address
+	UUID     common.Hash
+}
+
+// Equal returns true if i and other identify the same proposal, comparing the UUIDs by value.
+func (i LargePreimageIdentifier) Equal(other LargePreimageIdentifier) bool {
+	return i.Claimant == other.Claimant && compareUUIDs(i.UUID, other.UUID) == 0
+}
+
+// Key returns the map key for the proposal. Identifiers that are Equal have the same key.
+// A nil UUID has the same key as a zero UUID.
+func (i LargePreimageIdentifier) Key() LargePreimageKey {
+	key := LargePreimageKey{Claimant: i.Claimant}
+	if i.UUID != nil {
+		key.UUID = common.BigToHash(i.UUID)
+	}
+	return key
+}
+
+func (i LargePreimageIdentifier) String() string {
+	return fmt.Sprintf("{claimant: %v, uuid: %v}", i.Claimant, i.UUID)
+}
+
+// LargePreimageMetaData is the metadata tracked by the oracle for a large preimage proposal.
+type LargePreimageMetaData struct {
+	LargePreimageIdentifier
+
+	// Timestamp is the time at which the final leaf was added. Zero if the proposal is not yet finalized.
+	Timestamp       uint64
+	PartOffset      uint32
+	ClaimedSize     uint32
+	BlocksProcessed uint32
+	BytesProcessed  uint32
+	Countered       bool
+}
+
+// Equal returns true if m and other have the same values, comparing the UUIDs by value.
+func (m LargePreimageMetaData) Equal(other LargePreimageMetaData) bool {
+	return m.LargePreimageIdentifier.Equal(other.LargePreimageIdentifier) &&
+		m.Timestamp == other.Timestamp &&
+		m.PartOffset == other.PartOffset &&
+		m.ClaimedSize == other.ClaimedSize &&
+		m.BlocksProcessed == other.BlocksProcessed &&
+		m.BytesProcessed == other.BytesProcessed &&
+		m.Countered == other.Countered
+}
+
+// SortLargePreimageMetaData sorts proposals by claimant and then uuid, giving a deterministic order regardless of
+// the order the proposals were created in the oracle.
+func SortLargePreimageMetaData(proposals []LargePreimageMetaData) {
+	slices.SortStableFunc(proposals, func(a, b LargePreimageMetaData) int {
+		if c := bytes.Compare(a.Claimant[:], b.Claimant[:]); c != 0 {
+			return c
+		}
+		return compareUUIDs(a.UUID, b.UUID)
+	})
+}
+
+// compareUUIDs compares two uuids by value, ordering nil before any other value.
+func compareUUIDs(a, b *big.Int) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	default:
+		return a.Cmp(b)
+	}
+}
+
+// NewPreimageOracleDataFromProposal creates the PreimageOracleData matching the part of the preimage proposed by
+// the large preimage proposal. The proposal doesn't record the preimage key or data so the OracleData contains
+// only the claimed size prefix.
+func NewPreimageOracleDataFromProposal(meta LargePreimageMetaData) *types.PreimageOracleData {
+	data := make([]byte, types.PreimageSizePrefixLength)
+	binary.BigEndian.PutUint64(data, uint64(meta.ClaimedSize))
+	return types.NewPreimageOracleData(nil, data, meta.PartOffset)
+}
+
+// InputData is the data added to a large preimage proposal by a single addLeavesLPP transaction.
+type InputData struct {
+	Input       []byte
+	Commitments []common.Hash
+	Finalize    bool
+}
+
+// ChallengeParams is the data required to counter a large preimage proposal by proving that the state commitment
+// of the Poststate leaf does not match the result of absorbing its input.
+type ChallengeParams struct {
+	// StateMatrix is the state matrix matching the Prestate commitment.
+	// Unused when challenging the first leaf, which is absorbed into an empty state matrix.
+	StateMatrix   matrix.StateSnapshot
+	Prestate      Leaf
+	PrestateProof merkle.Proof
+
+	Poststate      Leaf
+	PoststateProof merkle.Proof
 }
 
 func NewPreimageOracleContract(addr common.Address, caller *batching.MultiCaller) (*PreimageOracleContract, error) {
+	// GetAbi only parses the ABI on first use and returns the cached instance after that, so the
+	// parsed ABI is shared by every oracle contract created.
 	mipsAbi, err := bindings.PreimageOracleMetaData.GetAbi()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load preimage oracle ABI: %w", err)
 	}
+	multicallAbi, err := bindings.MultiCall3MetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load multicall ABI: %w", err)
+	}
 
 	return &PreimageOracleContract{
 		addr:        addr,
 		multiCaller: caller,
 		contract:    batching.NewBoundContract(mipsAbi, addr),
+		multicall:   batching.NewBoundContract(multicallAbi, predeploys.MultiCall3Addr),
 	}, nil
 }
 
@@ -40,6 +238,568 @@ func (c *PreimageOracleContract) Addr() common.Address {
 }
 
 func (c *PreimageOracleContract) AddGlobalDataTx(data *types.PreimageOracleData) (txmgr.TxCandidate, error) {
-	call := c.contract.Call(methodLoadKeccak256PreimagePart, new(big.Int).SetUint64(uint64(data.OracleOffset)), data.GetPreimageWithoutSize())
+	call, err := c.loadKeccak256Call(data)
+	if err != nil {
+		return txmgr.TxCandidate{}, err
+	}
 	return call.ToTxCandidate()
 }
+
+// AddGlobalDataMulticallTx creates transactions loading each of the preimages into the oracle, packing the
+// loadKeccak256PreimagePart calls for multiple preimages into a single Multicall3 aggregate3 call.
+// Preimages are split across multiple transactions so the calldata of each is at most MaxTxCalldataSize.
+func (c *PreimageOracleContract) AddGlobalDataMulticallTx(datas []*types.PreimageOracleData) ([]txmgr.TxCandidate, error) {
+	var txs []txmgr.TxCandidate
+	var calls []bindings.Multicall3Call3
+	calldataSize := aggregate3BaseSize
+	for _, data := range datas {
+		call, err := c.loadKeccak256Call(data)
+		if err != nil {
+			return nil, err
+		}
+		callData, err := call.Pack()
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack preimage oracle call: %w", err)
+		}
+		size := aggregate3CallSize(callData)
+		if len(calls) > 0 && calldataSize+size > MaxTxCalldataSize {
+			tx, err := c.multicall.Call(methodAggregate3, calls).ToTxCandidate()
+			if err != nil {
+				return nil, err
+			}
+			txs = append(txs, tx)
+			calls = nil
+			calldataSize = aggregate3BaseSize
+		}
+		calls = append(calls, bindings.Multicall3Call3{Target: c.addr, CallData: callData})
+		calldataSize += size
+	}
+	if len(calls) > 0 {
+		tx, err := c.multicall.Call(methodAggregate3, calls).ToTxCandidate()
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// aggregate3BaseSize is the size of the aggregate3 calldata excluding the calls: the selector, offset and array length.
+const aggregate3BaseSize = 4 + 2*32
+
+// aggregate3CallSize returns the size a call with callData adds to the aggregate3 calldata: the offset to the call,
+// the target, allowFailure, offset and length of the call data, and the call data padded to a multiple of 32 bytes.
+func aggregate3CallSize(callData []byte) int {
+	return 5*32 + (len(callData)+31)/32*32
+}
+
+// loadKeccak256Call creates the call to load the part of the keccak256 preimage at data.OracleOffset.
+// The oracle prepends the 8-byte big-endian size word to the preimage itself before reading the part, so the
+// preimage is always passed without the size prefix, even when the offset is within the size word.
+func (c *PreimageOracleContract) loadKeccak256Call(data *types.PreimageOracleData) (*batching.ContractCall, error) {
+	if err := data.CheckPreimageSize(); err != nil {
+		return nil, err
+	}
+	if NeedsLargePreimage(data) {
+		return nil, fmt.Errorf("%w: %v bytes", ErrPreimageTooLargeForDirectLoad, len(data.OracleData))
+	}
+	return c.contract.Call(methodLoadKeccak256PreimagePart, new(big.Int).SetUint64(uint64(data.OracleOffset)), data.GetPreimageWithoutSize()), nil
+}
+
+func (c *PreimageOracleContract) InitLargePreimage(uuid *big.Int, partOffset uint32, claimedSize uint32) (txmgr.TxCandidate, error) {
+	call := c.contract.Call(methodInitLPP, uuid, partOffset, claimedSize)
+	return call.ToTxCandidate()
+}
+
+// AddLeaves creates the transactions to add leaves to the large preimage proposal with the specified uuid.
+// The leaves are split across transactions as described by SplitLeaves, with a transaction for each batch.
+// The proposal is only finalized by the last transaction, and only if finalize is true.
+func (c *PreimageOracleContract) AddLeaves(uuid *big.Int, partOffset uint32, leaves []Leaf, finalize bool, maxLeavesPerTx int) ([]txmgr.TxCandidate, error) {
+	batches, err := SplitLeaves(partOffset, leaves, finalize, maxLeavesPerTx)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]txmgr.TxCandidate, 0, len(batches))
+	for i, batch := range batches {
+		tx, err := c.addLeavesTx(uuid, batch, finalize && i == len(batches)-1)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// SplitLeaves splits the leaves of a large preimage proposal with the specified part offset into the batches added
+// by each transaction, with at most maxLeavesPerTx leaves in each.
+// maxLeavesPerTx must not exceed DefaultMaxLeavesPerTx so that each transaction stays within the calldata size limit.
+// The contract appends leaves in the order they are provided, so the leaf indices must be contiguous.
+// The contract reverts with PartOffsetOOB if the preimage part starts in the last 32 bytes of a batch that doesn't
+// finalize the proposal, so such a batch is ended before the leaf containing the part, which starts the next batch.
+// Returns ErrPartOffsetOOB if the part can't be moved out of the last 32 bytes of a batch.
+func SplitLeaves(partOffset uint32, leaves []Leaf, finalize bool, maxLeavesPerTx int) ([][]Leaf, error) {
+	if maxLeavesPerTx < 1 || maxLeavesPerTx > DefaultMaxLeavesPerTx {
+		return nil, fmt.Errorf("invalid max leaves per tx %v, must be between 1 and %v", maxLeavesPerTx, DefaultMaxLeavesPerTx)
+	}
+	if err := checkLeavesContiguous(leaves); err != nil {
+		return nil, err
+	}
+	var batches [][]Leaf
+	for start := 0; start < len(leaves); {
+		end := min(start+maxLeavesPerTx, len(leaves))
+		if !(finalize && end == len(leaves)) && partInLastWord(partOffset, leaves[start].Index.Uint64(), end-start) {
+			end--
+			if end == start {
+				return nil, fmt.Errorf("%w: part offset %v is in the last 32 bytes of leaf %v", ErrPartOffsetOOB, partOffset, leaves[start].Index)
+			}
+		}
+		batches = append(batches, leaves[start:end])
+		start = end
+	}
+	return batches, nil
+}
+
+// partInLastWord returns true if the preimage part at partOffset starts in the last 32 bytes of the input of a
+// batch of leafCount full leaves, starting with the leaf at index firstLeaf.
+func partInLastWord(partOffset uint32, firstLeaf uint64, leafCount int) bool {
+	// Parts starting in the size prefix are loaded when the first leaf is added, without a bounds check.
+	if partOffset < 8 {
+		return false
+	}
+	partStart := uint64(partOffset) - 8
+	batchStart := firstLeaf * matrix.LeafSize
+	batchEnd := batchStart + uint64(leafCount)*matrix.LeafSize
+	return partStart >= batchStart && partStart < batchEnd && partStart+32 >= batchEnd
+}
+
+// checkLeavesContiguous verifies that each leaf's index immediately follows the index of the previous leaf.
+func checkLeavesContiguous(leaves []Leaf) error {
+	if len(leaves) == 0 {
+		return nil
+	}
+	if leaves[0].Index == nil {
+		return fmt.Errorf("%w: leaf 0 has no index", ErrLeavesNotContiguous)
+	}
+	expected := new(big.Int).Set(leaves[0].Index)
+	for i, leaf := range leaves {
+		if leaf.Index == nil || leaf.Index.Cmp(expected) != 0 {
+			return fmt.Errorf("%w: leaf %v has index %v but expected %v", ErrLeavesNotContiguous, i, leaf.Index, expected)
+		}
+		expected.Add(expected, big.NewInt(1))
+	}
+	return nil
+}
+
+func (c *PreimageOracleContract) addLeavesTx(uuid *big.Int, leaves []Leaf, finalize bool) (txmgr.TxCandidate, error) {
+	input := make([]byte, 0, len(leaves)*matrix.LeafSize)
+	commitments := make([][32]byte, 0, len(leaves))
+	for _, leaf := range leaves {
+		input = append(input, leaf.Input...)
+		commitments = append(commitments, leaf.StateCommitment)
+	}
+	call := c.contract.Call(methodAddLeavesLPP, uuid, input, commitments, finalize)
+	return call.ToTxCandidate()
+}
+
+// Squeeze finalizes the large preimage proposal identified by ident once the challenge period has passed.
+// preState and postState must be the last two leaves of the proposal, and stateMatrix the state
+// matrix with the preState commitment.
+func (c *PreimageOracleContract) Squeeze(
+	ident LargePreimageIdentifier,
+	stateMatrix matrix.StateSnapshot,
+	preState Leaf,
+	preStateProof merkle.Proof,
+	postState Leaf,
+	postStateProof merkle.Proof,
+) (txmgr.TxCandidate, error) {
+	call := c.contract.Call(
+		methodSqueezeLPP,
+		ident.Claimant,
+		ident.UUID,
+		bindings.LibKeccakStateMatrix{State: stateMatrix},
+		toPreimageOracleLeaf(preState),
+		toProofArray(preStateProof),
+		toPreimageOracleLeaf(postState),
+		toProofArray(postStateProof),
+	)
+	return call.ToTxCandidate()
+}
+
+// ChallengeTx creates a transaction to counter the large preimage proposal identified by ident.
+// Challenges of the first leaf are sent to challengeFirstLPP, which doesn't require a prestate.
+func (c *PreimageOracleContract) ChallengeTx(ident LargePreimageIdentifier, challenge ChallengeParams) (txmgr.TxCandidate, error) {
+	if challenge.Poststate.Index == nil {
+		return txmgr.TxCandidate{}, fmt.Errorf("%w: poststate", ErrMissingLeafIndex)
+	}
+	var call *batching.ContractCall
+	if challenge.Poststate.Index.Sign() == 0 {
+		call = c.contract.Call(
+			methodChallengeFirstLPP,
+			ident.Claimant,
+			ident.UUID,
+			toPreimageOracleLeaf(challenge.Poststate),
+			toProofArray(challenge.PoststateProof),
+		)
+	} else {
+		if challenge.Prestate.Index == nil {
+			return txmgr.TxCandidate{}, fmt.Errorf("%w: prestate", ErrMissingLeafIndex)
+		}
+		call = c.contract.Call(
+			methodChallengeLPP,
+			ident.Claimant,
+			ident.UUID,
+			bindings.LibKeccakStateMatrix{State: challenge.StateMatrix},
+			toPreimageOracleLeaf(challenge.Prestate),
+			toProofArray(challenge.PrestateProof),
+			toPreimageOracleLeaf(challenge.Poststate),
+			toProofArray(challenge.PoststateProof),
+		)
+	}
+	return call.ToTxCandidate()
+}
+
+// ChallengePeriod returns the time in seconds that a finalized large preimage proposal can be challenged before it
+// can be squeezed.
+// The challenge period cannot change after deployment so the value is only requested once and then cached.
+func (c *PreimageOracleContract) ChallengePeriod(ctx context.Context) (uint64, error) {
+	c.challengePeriodLock.Lock()
+	defer c.challengePeriodLock.Unlock()
+	if c.challengePeriod != nil {
+		return *c.challengePeriod, nil
+	}
+	result, err := c.multiCaller.SingleCall(ctx, batching.BlockLatest, c.contract.Call(methodChallengePeriod))
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch challenge period: %w", err)
+	}
+	period := result.GetBigInt(0).Uint64()
+	c.challengePeriod = &period
+	return period, nil
+}
+
+// MaxProposalSize returns the size in bytes of the largest preimage that can be proposed as a large preimage.
+// The final leaf of a proposal is always a partial block so the preimage must be smaller than the max leaf count
+// of full leaves. The max leaf count is a constant so the value is only requested once and then cached.
+func (c *PreimageOracleContract) MaxProposalSize(ctx context.Context) (uint64, error) {
+	c.maxProposalSizeLock.Lock()
+	defer c.maxProposalSizeLock.Unlock()
+	if c.maxProposalSize != nil {
+		return *c.maxProposalSize, nil
+	}
+	result, err := c.multiCaller.SingleCall(ctx, batching.BlockLatest, c.contract.Call(methodMaxLeafCount))
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch max leaf count: %w", err)
+	}
+	size := result.GetBigInt(0).Uint64()*matrix.LeafSize - 1
+	c.maxProposalSize = &size
+	return size, nil
+}
+
+// GetProposalMetadata returns the metadata of the large preimage proposal identified by ident.
+// Proposals that have not been initialized have a ClaimedSize of 0.
+func (c *PreimageOracleContract) GetProposalMetadata(ctx context.Context, block batching.Block, ident LargePreimageIdentifier) (LargePreimageMetaData, error) {
+	result, err := c.multiCaller.SingleCall(ctx, block, c.contract.Call(methodProposalMetadata, ident.Claimant, ident.UUID))
+	if err != nil {
+		return LargePreimageMetaData{}, fmt.Errorf("failed to load proposal metadata: %w", err)
+	}
+	return c.decodeProposal(ident, result)
+}
+
+// GetProposalMetadatas returns the metadata of the large preimage proposals with the specified identifiers,
+// loading them all in a single batch. The returned metadata is in the same order as idents.
+func (c *PreimageOracleContract) GetProposalMetadatas(ctx context.Context, block batching.Block, idents []LargePreimageIdentifier) ([]LargePreimageMetaData, error) {
+	calls := make([]*batching.ContractCall, 0, len(idents))
+	for _, ident := range idents {
+		calls = append(calls, c.contract.Call(methodProposalMetadata, ident.Claimant, ident.UUID))
+	}
+	results, err := c.multiCaller.Call(ctx, block, calls...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load proposal metadata: %w", err)
+	}
+	proposals := make([]LargePreimageMetaData, 0, len(results))
+	for i, result := range results {
+		proposal, err := c.decodeProposal(idents[i], result)
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, proposal)
+	}
+	return proposals, nil
+}
+
+// ProposalExists returns true if a large preimage proposal has been initialized with the claimant and uuid of ident.
+// Initializing a proposal with the same claimant and uuid as an existing proposal doesn't revert. The oracle resets
+// the part offset and claimed size, adds a duplicate entry to its list of proposals and later leaves are appended
+// to the existing ones, corrupting the proposal. Callers must check the proposal doesn't exist before initializing it.
+func (c *PreimageOracleContract) ProposalExists(ctx context.Context, block batching.Block, ident LargePreimageIdentifier) (bool, error) {
+	metadata, err := c.GetProposalMetadata(ctx, block, ident)
+	if err != nil {
+		return false, err
+	}
+	return metadata.ClaimedSize != 0, nil
+}
+
+// IsProposalComplete returns true if the large preimage proposal identified by ident has
+// processed all of its claimed bytes. Squeezing an incomplete proposal reverts.
+// Proposals that have not been initialized are never complete.
+func (c *PreimageOracleContract) IsProposalComplete(ctx context.Context, block batching.Block, ident LargePreimageIdentifier) (bool, error) {
+	metadata, err := c.GetProposalMetadata(ctx, block, ident)
+	if err != nil {
+		return false, err
+	}
+	return metadata.ClaimedSize != 0 && metadata.BytesProcessed == metadata.ClaimedSize, nil
+}
+
+// IsProposalSqueezed returns true if the large preimage proposal identified by ident has
+// already been squeezed, making its preimage part available in the oracle. Squeezing again would revert.
+// The oracle doesn't record squeezes against the proposal, so this checks whether the part at the proposal's offset
+// is available for digest, the keccak256 hash of the preimage the proposal is for.
+func (c *PreimageOracleContract) IsProposalSqueezed(ctx context.Context, block batching.Block, ident LargePreimageIdentifier, digest common.Hash) (bool, error) {
+	metadata, err := c.GetProposalMetadata(ctx, block, ident)
+	if err != nil {
+		return false, err
+	}
+	// Only finalized proposals can be squeezed.
+	if metadata.ClaimedSize == 0 || metadata.Timestamp == 0 {
+		return false, nil
+	}
+	result, err := c.multiCaller.SingleCall(ctx, block, c.contract.Call(methodPreimagePartOk, digest, new(big.Int).SetUint64(uint64(metadata.PartOffset))))
+	if err != nil {
+		return false, fmt.Errorf("failed to load preimage part status: %w", err)
+	}
+	return result.GetBool(0), nil
+}
+
+// GetProposalTreeRoot returns the root of the merkle tree of leaves added to the large preimage proposal
+// identified by ident.
+func (c *PreimageOracleContract) GetProposalTreeRoot(ctx context.Context, block batching.Block, ident LargePreimageIdentifier) (common.Hash, error) {
+	result, err := c.multiCaller.SingleCall(ctx, block, c.contract.Call(methodGetTreeRootLPP, ident.Claimant, ident.UUID))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get tree root: %w", err)
+	}
+	return result.GetHash(0), nil
+}
+
+// GetInputDataBlocks returns the block numbers of the transactions that added leaves to the large preimage proposal
+// identified by ident, in the order they were added.
+// The oracle doesn't store the leaves so they must be read from the calldata of those transactions.
+func (c *PreimageOracleContract) GetInputDataBlocks(ctx context.Context, block batching.Block, ident LargePreimageIdentifier) ([]uint64, error) {
+	lenResult, err := c.multiCaller.SingleCall(ctx, block, c.contract.Call(methodProposalBlocksLen, ident.Claimant, ident.UUID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load proposal blocks length: %w", err)
+	}
+	count := lenResult.GetBigInt(0).Uint64()
+
+	calls := make([]*batching.ContractCall, count)
+	for i := uint64(0); i < count; i++ {
+		calls[i] = c.contract.Call(methodProposalBlocks, ident.Claimant, ident.UUID, new(big.Int).SetUint64(i))
+	}
+	results, err := c.multiCaller.Call(ctx, block, calls...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load proposal blocks: %w", err)
+	}
+	blockNums := make([]uint64, 0, count)
+	for _, result := range results {
+		blockNums = append(blockNums, result.GetUint64(0))
+	}
+	return blockNums, nil
+}
+
+// DecodeInputData decodes the calldata of an addLeavesLPP transaction, returning the proposal uuid and the data added.
+func (c *PreimageOracleContract) DecodeInputData(data []byte) (*big.Int, InputData, error) {
+	method, args, err := c.contract.DecodeCall(data)
+	if err != nil {
+		return nil, InputData{}, fmt.Errorf("failed to decode input data: %w", err)
+	}
+	if method != methodAddLeavesLPP {
+		return nil, InputData{}, fmt.Errorf("%w: %v", ErrInvalidAddLeavesCall, method)
+	}
+	uuid := args.GetBigInt(0)
+	var input []byte
+	args.GetStruct(1, &input)
+	var commitments [][32]byte
+	args.GetStruct(2, &commitments)
+	stateCommitments := make([]common.Hash, 0, len(commitments))
+	for _, commitment := range commitments {
+		stateCommitments = append(stateCommitments, commitment)
+	}
+	return uuid, InputData{
+		Input:       input,
+		Commitments: stateCommitments,
+		Finalize:    args.GetBool(3),
+	}, nil
+}
+
+// GetActivePreimages returns the metadata of all large preimage proposals known to the oracle at the specified block.
+func (c *PreimageOracleContract) GetActivePreimages(ctx context.Context, blockHash common.Hash) ([]LargePreimageMetaData, error) {
+	return c.getActivePreimages(ctx, batching.BlockByHash(blockHash), allClaimants)
+}
+
+// GetActivePreimagesByClaimant returns the metadata of the large preimage proposals created by claimant that are known
+// to the oracle at the specified block.
+// Proposals are stored in a single list so all proposals are loaded, but metadata is only loaded for matching proposals.
+func (c *PreimageOracleContract) GetActivePreimagesByClaimant(ctx context.Context, block batching.Block, claimant common.Address) ([]LargePreimageMetaData, error) {
+	return c.getActivePreimages(ctx, block, func(proposalClaimant common.Address) bool {
+		return proposalClaimant == claimant
+	})
+}
+
+// CountActivePreimages returns the number of countered and uncountered large preimage proposals known to the oracle
+// at the specified block.
+func (c *PreimageOracleContract) CountActivePreimages(ctx context.Context, block batching.Block) (gameTypes.ActivePreimageCounts, error) {
+	proposals, err := c.getActivePreimages(ctx, block, allClaimants)
+	if err != nil {
+		return gameTypes.ActivePreimageCounts{}, err
+	}
+	var counts gameTypes.ActivePreimageCounts
+	for _, proposal := range proposals {
+		if proposal.Countered {
+			counts.Countered++
+		} else {
+			counts.Uncountered++
+		}
+	}
+	return counts, nil
+}
+
+func allClaimants(common.Address) bool {
+	return true
+}
+
+// getActivePreimages loads the metadata of proposals at block, skipping any created by a claimant that include rejects.
+// The oracle lists a proposal again each time it is initialized, so duplicate entries are only included once.
+func (c *PreimageOracleContract) getActivePreimages(ctx context.Context, block batching.Block, include func(claimant common.Address) bool) ([]LargePreimageMetaData, error) {
+	countResult, err := c.multiCaller.SingleCall(ctx, block, c.contract.Call(methodProposalCount))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load proposal count: %w", err)
+	}
+	count := countResult.GetBigInt(0).Uint64()
+
+	calls := make([]*batching.ContractCall, count)
+	for i := uint64(0); i < count; i++ {
+		calls[i] = c.contract.Call(methodProposals, new(big.Int).SetUint64(i))
+	}
+	results, err := c.multiCaller.Call(ctx, block, calls...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load proposals: %w", err)
+	}
+
+	type proposalKey struct {
+		claimant common.Address
+		uuid     common.Hash
+	}
+	seen := make(map[proposalKey]bool)
+	var idents []LargePreimageIdentifier
+	for _, result := range results {
+		claimant := result.GetAddress(0)
+		if !include(claimant) {
+			continue
+		}
+		uuid := result.GetBigInt(1)
+		key := proposalKey{claimant, common.BigToHash(uuid)}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		idents = append(idents, LargePreimageIdentifier{Claimant: claimant, UUID: uuid})
+	}
+	return c.GetProposalMetadatas(ctx, block, idents)
+}
+
+func (c *PreimageOracleContract) decodeProposal(ident LargePreimageIdentifier, result *batching.CallResult) (LargePreimageMetaData, error) {
+	if result.Len() != 1 {
+		return LargePreimageMetaData{}, fmt.Errorf("%w for proposal %v: expected 1 value but got %v",
+			ErrMalformedProposalMetadata, ident, result.Len())
+	}
+	meta := metadata(result.GetHash(0))
+	return LargePreimageMetaData{
+		LargePreimageIdentifier: ident,
+		Timestamp:               meta.timestamp(),
+		PartOffset:              meta.partOffset(),
+		ClaimedSize:             meta.claimedSize(),
+		BlocksProcessed:         meta.blocksProcessed(),
+		BytesProcessed:          meta.bytesProcessed(),
+		Countered:               meta.countered(),
+	}, nil
+}
+
+// MerkleRoot returns the root of the merkle tree of leaves, matching the tree the oracle builds as the leaves are
+// added to a large preimage proposal.
+func MerkleRoot(leaves []Leaf) (common.Hash, error) {
+	tree, err := newLeavesTree(leaves)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return tree.RootHash(), nil
+}
+
+// MerkleProof returns the proof of the leaf at index against the merkle tree of leaves, in the form passed to the
+// oracle when challenging or squeezing a large preimage proposal.
+func MerkleProof(leaves []Leaf, index int) ([][32]byte, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("%w: index %v with %v leaves", merkle.ErrIndexOutOfBounds, index, len(leaves))
+	}
+	tree, err := newLeavesTree(leaves)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := tree.ProofAtIndex(uint64(index))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create proof: %w", err)
+	}
+	return toProofArray(proof), nil
+}
+
+func newLeavesTree(leaves []Leaf) (*merkle.BinaryMerkleTree, error) {
+	tree := merkle.NewBinaryMerkleTree()
+	for _, leaf := range leaves {
+		if err := tree.AddLeaf(leaf.Hash()); err != nil {
+			return nil, fmt.Errorf("failed to build merkle tree: %w", err)
+		}
+	}
+	return tree, nil
+}
+
+func toProofArray(proof merkle.Proof) [][32]byte {
+	nodes := make([][32]byte, len(proof))
+	for i, node := range proof {
+		nodes[i] = node
+	}
+	return nodes
+}
+
+// metadata is the packed LPPMetaData stored by the oracle for each large preimage proposal.
+// ┌─────────────┬────────────────────────────────────────────┐
+// │ Bit Offsets │                Description                 │
+// ├─────────────┼────────────────────────────────────────────┤
+// │ [0, 64)     │ Timestamp (Finalized - All data available) │
+// │ [64, 96)    │ Part Offset                                │
+// │ [96, 128)   │ Claimed Size                               │
+// │ [128, 160)  │ Blocks Processed (Inclusive of Padding)    │
+// │ [160, 192)  │ Bytes Processed (Non-inclusive of Padding) │
+// │ [192, 256)  │ Countered                                  │
+// └─────────────┴────────────────────────────────────────────┘
+type metadata [32]byte
+
+func (m metadata) timestamp() uint64 {
+	return binary.BigEndian.Uint64(m[0:8])
+}
+
+func (m metadata) partOffset() uint32 {
+	return binary.BigEndian.Uint32(m[8:12])
+}
+
+func (m metadata) claimedSize() uint32 {
+	return binary.BigEndian.Uint32(m[12:16])
+}
+
+func (m metadata) blocksProcessed() uint32 {
+	return binary.BigEndian.Uint32(m[16:20])
+}
+
+func (m metadata) bytesProcessed() uint32 {
+	return binary.BigEndian.Uint32(m[20:24])
+}
+
+func (m metadata) countered() bool {
+	return binary.BigEndian.Uint64(m[24:32]) != 0
+}
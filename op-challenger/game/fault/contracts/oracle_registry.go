@@ -0,0 +1,55 @@
+package contracts
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-service/sources/batching"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// OracleAddrLoader loads the address of the preimage oracle used by a dispute game.
+type OracleAddrLoader interface {
+	GetOracleAddr(ctx context.Context) (common.Address, error)
+}
+
+// OracleRegistry lazily creates and caches a PreimageOracleContract for each oracle address so that games
+// using the same oracle deployment share a single contract binding, including its cached values.
+type OracleRegistry struct {
+	multiCaller *batching.MultiCaller
+
+	lock    sync.Mutex
+	oracles map[common.Address]*PreimageOracleContract
+}
+
+func NewOracleRegistry(caller *batching.MultiCaller) *OracleRegistry {
+	return &OracleRegistry{
+		multiCaller: caller,
+		oracles:     make(map[common.Address]*PreimageOracleContract),
+	}
+}
+
+// Oracle returns the PreimageOracleContract for addr, creating it if it is not already cached.
+func (r *OracleRegistry) Oracle(addr common.Address) (*PreimageOracleContract, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if oracle, ok := r.oracles[addr]; ok {
+		return oracle, nil
+	}
+	oracle, err := NewPreimageOracleContract(addr, r.multiCaller)
+	if err != nil {
+		return nil, err
+	}
+	r.oracles[addr] = oracle
+	return oracle, nil
+}
+
+// GameOracle returns the PreimageOracleContract used by game.
+func (r *OracleRegistry) GameOracle(ctx context.Context, game OracleAddrLoader) (*PreimageOracleContract, error) {
+	addr, err := game.GetOracleAddr(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load oracle address: %w", err)
+	}
+	return r.Oracle(addr)
+}
@@ -0,0 +1,65 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/sources/batching"
+	batchingTest "github.com/ethereum-optimism/optimism/op-service/sources/batching/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOracleRegistry_Oracle(t *testing.T) {
+	registry := NewOracleRegistry(batching.NewMultiCaller(batchingTest.NewAbiBasedRpc(t, oracleAddr, nil), batching.DefaultBatchSize))
+
+	t.Run("SameAddressCached", func(t *testing.T) {
+		oracle1, err := registry.Oracle(oracleAddr)
+		require.NoError(t, err)
+		oracle2, err := registry.Oracle(oracleAddr)
+		require.NoError(t, err)
+		require.Same(t, oracle1, oracle2)
+		require.Equal(t, oracleAddr, oracle1.Addr())
+	})
+
+	t.Run("DifferentAddressesDistinct", func(t *testing.T) {
+		otherAddr := common.Address{0x55, 0x66}
+		oracle1, err := registry.Oracle(oracleAddr)
+		require.NoError(t, err)
+		oracle2, err := registry.Oracle(otherAddr)
+		require.NoError(t, err)
+		require.NotSame(t, oracle1, oracle2)
+		require.Equal(t, oracleAddr, oracle1.Addr())
+		require.Equal(t, otherAddr, oracle2.Addr())
+	})
+}
+
+func TestOracleRegistry_GameOracle(t *testing.T) {
+	registry := NewOracleRegistry(batching.NewMultiCaller(batchingTest.NewAbiBasedRpc(t, oracleAddr, nil), batching.DefaultBatchSize))
+
+	t.Run("Success", func(t *testing.T) {
+		oracle, err := registry.GameOracle(context.Background(), &stubOracleAddrLoader{addr: oracleAddr})
+		require.NoError(t, err)
+		require.Equal(t, oracleAddr, oracle.Addr())
+
+		cached, err := registry.Oracle(oracleAddr)
+		require.NoError(t, err)
+		require.Same(t, cached, oracle)
+	})
+
+	t.Run("LoadAddrFails", func(t *testing.T) {
+		expectedErr := errors.New("boom")
+		_, err := registry.GameOracle(context.Background(), &stubOracleAddrLoader{err: expectedErr})
+		require.ErrorIs(t, err, expectedErr)
+	})
+}
+
+type stubOracleAddrLoader struct {
+	addr common.Address
+	err  error
+}
+
+func (s *stubOracleAddrLoader) GetOracleAddr(_ context.Context) (common.Address, error) {
+	return s.addr, s.err
+}
@@ -1,24 +1,30 @@
 package contracts
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"math/big"
 	"testing"
 
 	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
 	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/types"
+	"github.com/ethereum-optimism/optimism/op-challenger/game/keccak/matrix"
+	"github.com/ethereum-optimism/optimism/op-challenger/game/keccak/merkle"
+	gameTypes "github.com/ethereum-optimism/optimism/op-challenger/game/types"
 	"github.com/ethereum-optimism/optimism/op-service/sources/batching"
 	batchingTest "github.com/ethereum-optimism/optimism/op-service/sources/batching/test"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/stretchr/testify/require"
 )
 
 func TestPreimageOracleContract_LoadKeccak256(t *testing.T) {
-	oracleAbi, err := bindings.PreimageOracleMetaData.GetAbi()
-	require.NoError(t, err)
-
-	stubRpc := batchingTest.NewAbiBasedRpc(t, oracleAddr, oracleAbi)
-	oracleContract, err := NewPreimageOracleContract(oracleAddr, batching.NewMultiCaller(stubRpc, batching.DefaultBatchSize))
-	require.NoError(t, err)
+	stubRpc, oracleContract := setupPreimageOracleTest(t)
 
 	data := &types.PreimageOracleData{
 		OracleKey:    common.Hash{0xcc}.Bytes(),
@@ -34,3 +40,1065 @@ func TestPreimageOracleContract_LoadKeccak256(t *testing.T) {
 	require.NoError(t, err)
 	stubRpc.VerifyTxCandidate(tx)
 }
+
+func TestPreimageOracleContract_LoadKeccak256PartOffsets(t *testing.T) {
+	preimage := make([]byte, 50)
+	for i := range preimage {
+		preimage[i] = byte(i + 1)
+	}
+	oracleData := binary.BigEndian.AppendUint64(nil, uint64(len(preimage)))
+	oracleData = append(oracleData, preimage...)
+
+	tests := []struct {
+		name   string
+		offset uint32
+	}{
+		{name: "SizeWord", offset: 0},
+		{name: "WithinSizeWord", offset: 4},
+		{name: "FirstDataWord", offset: 8},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			stubRpc, oracleContract := setupPreimageOracleTest(t)
+			data := types.NewPreimageOracleData(common.Hash{0xcc}.Bytes(), oracleData, test.offset)
+			stubRpc.SetResponse(oracleAddr, methodLoadKeccak256PreimagePart, batching.BlockLatest, []interface{}{
+				new(big.Int).SetUint64(uint64(test.offset)),
+				preimage,
+			}, nil)
+
+			tx, err := oracleContract.AddGlobalDataTx(data)
+			require.NoError(t, err)
+			stubRpc.VerifyTxCandidate(tx)
+
+			// Read the part the same way the oracle does: prepend the size word to the supplied preimage.
+			oracleAbi, err := bindings.PreimageOracleMetaData.GetAbi()
+			require.NoError(t, err)
+			args, err := oracleAbi.Methods[methodLoadKeccak256PreimagePart].Inputs.Unpack(tx.TxData[4:])
+			require.NoError(t, err)
+			supplied := args[1].([]byte)
+			contractData := binary.BigEndian.AppendUint64(nil, uint64(len(supplied)))
+			contractData = append(contractData, supplied...)
+			contractData = append(contractData, make([]byte, 32)...)
+			expected := append(append([]byte{}, oracleData...), make([]byte, 32)...)
+			require.Equal(t, expected[test.offset:test.offset+32], contractData[test.offset:test.offset+32])
+		})
+	}
+}
+
+func TestPreimageOracleContract_LoadKeccak256TooLarge(t *testing.T) {
+	stubRpc, oracleContract := setupPreimageOracleTest(t)
+
+	data := types.NewPreimageOracleData(common.Hash{0xcc}.Bytes(), make([]byte, MaxDirectPreimageSize), 0)
+	stubRpc.SetResponse(oracleAddr, methodLoadKeccak256PreimagePart, batching.BlockLatest, []interface{}{
+		new(big.Int).SetUint64(uint64(data.OracleOffset)),
+		data.GetPreimageWithoutSize(),
+	}, nil)
+	tx, err := oracleContract.AddGlobalDataTx(data)
+	require.NoError(t, err)
+	stubRpc.VerifyTxCandidate(tx)
+
+	data = types.NewPreimageOracleData(common.Hash{0xcc}.Bytes(), make([]byte, MaxDirectPreimageSize+1), 0)
+	_, err = oracleContract.AddGlobalDataTx(data)
+	require.ErrorIs(t, err, ErrPreimageTooLargeForDirectLoad)
+}
+
+func TestPreimageOracleContract_LoadKeccak256MissingSize(t *testing.T) {
+	_, oracleContract := setupPreimageOracleTest(t)
+	data := types.NewPreimageOracleData(common.Hash{0xcc}.Bytes(), make([]byte, 7), 0)
+	_, err := oracleContract.AddGlobalDataTx(data)
+	require.ErrorIs(t, err, types.ErrMissingPreimageSize)
+}
+
+func TestPreimageOracleContract_AddGlobalDataMulticallTx(t *testing.T) {
+	t.Run("SingleTx", func(t *testing.T) {
+		stubRpc, oracleContract := setupPreimageOracleTest(t)
+		multicallAbi, err := bindings.MultiCall3MetaData.GetAbi()
+		require.NoError(t, err)
+		stubRpc.AddContract(predeploys.MultiCall3Addr, multicallAbi)
+		data1 := types.NewPreimageOracleData(common.Hash{0xcc}.Bytes(), []byte{0, 0, 0, 0, 0, 0, 0, 3, 1, 2, 3}, 0)
+		data2 := types.NewPreimageOracleData(common.Hash{0xdd}.Bytes(), []byte{0, 0, 0, 0, 0, 0, 0, 2, 4, 5}, 8)
+		calls := make([]bindings.Multicall3Call3, 0, 2)
+		for _, data := range []*types.PreimageOracleData{data1, data2} {
+			callData, err := oracleContract.contract.Call(methodLoadKeccak256PreimagePart,
+				new(big.Int).SetUint64(uint64(data.OracleOffset)), data.GetPreimageWithoutSize()).Pack()
+			require.NoError(t, err)
+			calls = append(calls, bindings.Multicall3Call3{Target: oracleAddr, CallData: callData})
+		}
+		stubRpc.SetResponse(predeploys.MultiCall3Addr, methodAggregate3, batching.BlockLatest, []interface{}{calls}, nil)
+
+		txs, err := oracleContract.AddGlobalDataMulticallTx([]*types.PreimageOracleData{data1, data2})
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		stubRpc.VerifyTxCandidate(txs[0])
+		require.Equal(t, predeploys.MultiCall3Addr, *txs[0].To)
+
+		// Decode the packed calls back to the original preimages.
+		method, args, err := oracleContract.multicall.DecodeCall(txs[0].TxData)
+		require.NoError(t, err)
+		require.Equal(t, methodAggregate3, method)
+		var packed []bindings.Multicall3Call3
+		args.GetStruct(0, &packed)
+		require.Len(t, packed, 2)
+		for i, data := range []*types.PreimageOracleData{data1, data2} {
+			require.Equal(t, oracleAddr, packed[i].Target)
+			require.False(t, packed[i].AllowFailure)
+			method, result, err := oracleContract.contract.DecodeCall(packed[i].CallData)
+			require.NoError(t, err)
+			require.Equal(t, methodLoadKeccak256PreimagePart, method)
+			require.Equal(t, uint64(data.OracleOffset), result.GetBigInt(0).Uint64())
+			var preimage []byte
+			result.GetStruct(1, &preimage)
+			require.Equal(t, data.GetPreimageWithoutSize(), preimage)
+		}
+	})
+
+	t.Run("SplitByCalldataSize", func(t *testing.T) {
+		_, oracleContract := setupPreimageOracleTest(t)
+		var datas []*types.PreimageOracleData
+		for i := 0; i < 20; i++ {
+			datas = append(datas, types.NewPreimageOracleData(common.Hash{byte(i)}.Bytes(), make([]byte, MaxDirectPreimageSize), 0))
+		}
+		txs, err := oracleContract.AddGlobalDataMulticallTx(datas)
+		require.NoError(t, err)
+		require.Greater(t, len(txs), 1)
+		for _, tx := range txs {
+			require.LessOrEqual(t, len(tx.TxData), MaxTxCalldataSize)
+		}
+	})
+
+	t.Run("NoPreimages", func(t *testing.T) {
+		_, oracleContract := setupPreimageOracleTest(t)
+		txs, err := oracleContract.AddGlobalDataMulticallTx(nil)
+		require.NoError(t, err)
+		require.Empty(t, txs)
+	})
+
+	t.Run("PreimageTooLarge", func(t *testing.T) {
+		_, oracleContract := setupPreimageOracleTest(t)
+		data := types.NewPreimageOracleData(common.Hash{0xcc}.Bytes(), make([]byte, MaxDirectPreimageSize+1), 0)
+		_, err := oracleContract.AddGlobalDataMulticallTx([]*types.PreimageOracleData{data})
+		require.ErrorIs(t, err, ErrPreimageTooLargeForDirectLoad)
+	})
+}
+
+func TestNeedsLargePreimage(t *testing.T) {
+	tests := []struct {
+		size     int
+		expected bool
+	}{
+		{0, false},
+		{8, false},
+		{MaxDirectPreimageSize - 1, false},
+		{MaxDirectPreimageSize, false},
+		{MaxDirectPreimageSize + 1, true},
+		{MaxDirectPreimageSize * 2, true},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("Size-%v", test.size), func(t *testing.T) {
+			data := &types.PreimageOracleData{OracleData: make([]byte, test.size)}
+			require.Equal(t, test.expected, NeedsLargePreimage(data))
+		})
+	}
+}
+
+func TestPreimageOracleContract_InitLargePreimage(t *testing.T) {
+	stubRpc, oracle := setupPreimageOracleTest(t)
+
+	uuid := big.NewInt(123)
+	partOffset := uint32(1)
+	claimedSize := uint32(2)
+	stubRpc.SetResponse(oracleAddr, methodInitLPP, batching.BlockLatest, []interface{}{
+		uuid,
+		partOffset,
+		claimedSize,
+	}, nil)
+
+	tx, err := oracle.InitLargePreimage(uuid, partOffset, claimedSize)
+	require.NoError(t, err)
+	stubRpc.VerifyTxCandidate(tx)
+}
+
+func TestPreimageOracleContract_AddLeaves(t *testing.T) {
+	tests := []struct {
+		name           string
+		leafCount      int
+		partOffset     uint32
+		finalize       bool
+		maxLeavesPerTx int
+		txLeaves       []int
+	}{
+		{"SingleLeaf", 1, 0, true, DefaultMaxLeavesPerTx, []int{1}},
+		{"NotFinalized", 3, 0, false, DefaultMaxLeavesPerTx, []int{3}},
+		{"FullTx", DefaultMaxLeavesPerTx, 0, true, DefaultMaxLeavesPerTx, []int{DefaultMaxLeavesPerTx}},
+		{"MultipleTxs", DefaultMaxLeavesPerTx*2 + 10, 0, true, DefaultMaxLeavesPerTx, []int{DefaultMaxLeavesPerTx, DefaultMaxLeavesPerTx, 10}},
+		{"MultipleTxsNotFinalized", DefaultMaxLeavesPerTx + 1, 0, false, DefaultMaxLeavesPerTx, []int{DefaultMaxLeavesPerTx, 1}},
+		{"OneLeafPerTx", 3, 0, true, 1, []int{1, 1, 1}},
+		{"CustomLimit", 25, 0, true, 10, []int{10, 10, 5}},
+		{"CustomLimitExact", 20, 0, true, 10, []int{10, 10}},
+		{"PartInSizePrefix", 25, 4, true, 10, []int{10, 10, 5}},
+		{"PartBeforeLastWordOfTx", 25, 8 + 10*matrix.LeafSize - 33, true, 10, []int{10, 10, 5}},
+		{"PartStartsLastWordOfTx", 25, 8 + 10*matrix.LeafSize - 32, true, 10, []int{9, 10, 6}},
+		{"PartInLastWordOfTx", 25, 8 + 10*matrix.LeafSize - 1, true, 10, []int{9, 10, 6}},
+		{"PartInLastWordOfLaterTx", 25, 8 + 20*matrix.LeafSize - 10, true, 10, []int{10, 9, 6}},
+		{"PartInLastWordOfFinalTx", 25, 8 + 25*matrix.LeafSize - 10, true, 10, []int{10, 10, 5}},
+		{"PartAtStartOfTx", 25, 8 + 10*matrix.LeafSize, true, 10, []int{10, 10, 5}},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			stubRpc, oracle := setupPreimageOracleTest(t)
+			uuid := big.NewInt(123)
+			leaves := make([]Leaf, test.leafCount)
+			for i := range leaves {
+				leaves[i] = Leaf{
+					Input:           bytes.Repeat([]byte{byte(i)}, matrix.LeafSize),
+					Index:           big.NewInt(int64(i)),
+					StateCommitment: common.Hash{byte(i), byte(i >> 8)},
+				}
+			}
+
+			start := 0
+			for i, count := range test.txLeaves {
+				var input []byte
+				var commitments [][32]byte
+				for _, leaf := range leaves[start : start+count] {
+					input = append(input, leaf.Input...)
+					commitments = append(commitments, leaf.StateCommitment)
+				}
+				finalize := test.finalize && i == len(test.txLeaves)-1
+				stubRpc.SetResponse(oracleAddr, methodAddLeavesLPP, batching.BlockLatest, []interface{}{
+					uuid,
+					input,
+					commitments,
+					finalize,
+				}, nil)
+				start += count
+			}
+
+			txs, err := oracle.AddLeaves(uuid, test.partOffset, leaves, test.finalize, test.maxLeavesPerTx)
+			require.NoError(t, err)
+			require.Len(t, txs, len(test.txLeaves))
+			for _, tx := range txs {
+				require.LessOrEqual(t, len(tx.TxData), MaxTxCalldataSize)
+				stubRpc.VerifyTxCandidate(tx)
+			}
+		})
+	}
+
+	t.Run("FullBatchWithinCalldataLimit", func(t *testing.T) {
+		_, oracle := setupPreimageOracleTest(t)
+		leaves := make([]Leaf, DefaultMaxLeavesPerTx)
+		for i := range leaves {
+			leaves[i] = Leaf{Input: make([]byte, matrix.LeafSize), Index: big.NewInt(int64(i))}
+		}
+		txs, err := oracle.AddLeaves(big.NewInt(123), 0, leaves, false, DefaultMaxLeavesPerTx)
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		require.LessOrEqual(t, len(txs[0].TxData), MaxTxCalldataSize)
+	})
+
+	t.Run("InvalidMaxLeavesPerTx", func(t *testing.T) {
+		_, oracle := setupPreimageOracleTest(t)
+		leaves := []Leaf{{Input: []byte{1}, Index: big.NewInt(0)}}
+		_, err := oracle.AddLeaves(big.NewInt(123), 0, leaves, true, 0)
+		require.Error(t, err)
+		_, err = oracle.AddLeaves(big.NewInt(123), 0, leaves, true, DefaultMaxLeavesPerTx+1)
+		require.Error(t, err)
+	})
+
+	t.Run("PartInLastWordOfSingleLeafTx", func(t *testing.T) {
+		_, oracle := setupPreimageOracleTest(t)
+		leaves := make([]Leaf, 3)
+		for i := range leaves {
+			leaves[i] = Leaf{Input: make([]byte, matrix.LeafSize), Index: big.NewInt(int64(i))}
+		}
+		_, err := oracle.AddLeaves(big.NewInt(123), 8+2*matrix.LeafSize-10, leaves, true, 1)
+		require.ErrorIs(t, err, ErrPartOffsetOOB)
+		// The part is only allowed in the last 32 bytes of the final leaf.
+		txs, err := oracle.AddLeaves(big.NewInt(123), 8+3*matrix.LeafSize-10, leaves, true, 1)
+		require.NoError(t, err)
+		require.Len(t, txs, 3)
+	})
+
+	t.Run("OffsetStart", func(t *testing.T) {
+		_, oracle := setupPreimageOracleTest(t)
+		leaves := []Leaf{{Input: []byte{1}, Index: big.NewInt(5)}, {Input: []byte{2}, Index: big.NewInt(6)}}
+		_, err := oracle.AddLeaves(big.NewInt(123), 0, leaves, true, DefaultMaxLeavesPerTx)
+		require.NoError(t, err)
+	})
+
+	t.Run("OutOfOrderLeaves", func(t *testing.T) {
+		_, oracle := setupPreimageOracleTest(t)
+		leaves := []Leaf{
+			{Input: []byte{1}, Index: big.NewInt(0)},
+			{Input: []byte{2}, Index: big.NewInt(2)},
+			{Input: []byte{3}, Index: big.NewInt(1)},
+		}
+		_, err := oracle.AddLeaves(big.NewInt(123), 0, leaves, true, DefaultMaxLeavesPerTx)
+		require.ErrorIs(t, err, ErrLeavesNotContiguous)
+	})
+
+	t.Run("MissingIndex", func(t *testing.T) {
+		_, oracle := setupPreimageOracleTest(t)
+		leaves := []Leaf{{Input: []byte{1}, Index: big.NewInt(0)}, {Input: []byte{2}}}
+		_, err := oracle.AddLeaves(big.NewInt(123), 0, leaves, true, DefaultMaxLeavesPerTx)
+		require.ErrorIs(t, err, ErrLeavesNotContiguous)
+	})
+}
+
+func TestPreimageOracleContract_ProposalMetadata(t *testing.T) {
+	stubRpc, oracle := setupPreimageOracleTest(t)
+	claimant := common.Address{0xaa}
+	uuid := big.NewInt(4444)
+	block := batching.BlockByNumber(223)
+
+	var meta metadata
+	binary.BigEndian.PutUint64(meta[0:8], 1234)
+	binary.BigEndian.PutUint32(meta[8:12], 16)
+	binary.BigEndian.PutUint32(meta[12:16], 5000)
+	binary.BigEndian.PutUint32(meta[16:20], 37)
+	binary.BigEndian.PutUint32(meta[20:24], 4896)
+	binary.BigEndian.PutUint64(meta[24:32], 1)
+	stubRpc.SetResponse(oracleAddr, methodProposalMetadata, block, []interface{}{claimant, uuid}, []interface{}{meta})
+
+	actual, err := oracle.GetProposalMetadata(context.Background(), block, LargePreimageIdentifier{Claimant: claimant, UUID: uuid})
+	require.NoError(t, err)
+	require.Equal(t, LargePreimageMetaData{
+		LargePreimageIdentifier: LargePreimageIdentifier{Claimant: claimant, UUID: uuid},
+		Timestamp:               1234,
+		PartOffset:              16,
+		ClaimedSize:             5000,
+		BlocksProcessed:         37,
+		BytesProcessed:          4896,
+		Countered:               true,
+	}, actual)
+}
+
+func TestPreimageOracleContract_GetProposalMetadatas(t *testing.T) {
+	stubRpc, _ := setupPreimageOracleTest(t)
+	counter := &batchCountingRpc{AbiBasedRpc: stubRpc}
+	oracle, err := NewPreimageOracleContract(oracleAddr, batching.NewMultiCaller(counter, batching.DefaultBatchSize))
+	require.NoError(t, err)
+	block := batching.BlockByNumber(223)
+	proposals := testProposals()
+	idents := make([]LargePreimageIdentifier, 0, len(proposals))
+	for _, proposal := range proposals {
+		idents = append(idents, proposal.LargePreimageIdentifier)
+	}
+	setupProposals(stubRpc, block, proposals)
+
+	actual, err := oracle.GetProposalMetadatas(context.Background(), block, idents)
+	require.NoError(t, err)
+	require.Len(t, idents, 3)
+	require.Equal(t, proposals, actual)
+	// All metadata is loaded in a single batch request.
+	require.Equal(t, 1, counter.batches)
+}
+
+// batchCountingRpc counts the number of batch requests made.
+type batchCountingRpc struct {
+	*batchingTest.AbiBasedRpc
+	batches int
+}
+
+func (r *batchCountingRpc) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	r.batches++
+	return r.AbiBasedRpc.BatchCallContext(ctx, b)
+}
+
+func TestLargePreimageIdentifier(t *testing.T) {
+	claimant := common.Address{0xaa}
+	ident := LargePreimageIdentifier{Claimant: claimant, UUID: big.NewInt(1111)}
+
+	t.Run("EqualByValue", func(t *testing.T) {
+		require.True(t, ident.Equal(LargePreimageIdentifier{Claimant: claimant, UUID: big.NewInt(1111)}))
+		require.False(t, ident.Equal(LargePreimageIdentifier{Claimant: claimant, UUID: big.NewInt(2222)}))
+		require.False(t, ident.Equal(LargePreimageIdentifier{Claimant: common.Address{0xbb}, UUID: big.NewInt(1111)}))
+		require.False(t, ident.Equal(LargePreimageIdentifier{Claimant: claimant}))
+		require.True(t, LargePreimageIdentifier{Claimant: claimant}.Equal(LargePreimageIdentifier{Claimant: claimant}))
+	})
+
+	t.Run("KeyUsableInMaps", func(t *testing.T) {
+		seen := map[LargePreimageKey]bool{ident.Key(): true}
+		require.True(t, seen[LargePreimageIdentifier{Claimant: claimant, UUID: big.NewInt(1111)}.Key()])
+		require.False(t, seen[LargePreimageIdentifier{Claimant: claimant, UUID: big.NewInt(2222)}.Key()])
+		require.False(t, seen[LargePreimageIdentifier{Claimant: common.Address{0xbb}, UUID: big.NewInt(1111)}.Key()])
+	})
+
+	t.Run("NilUUIDKey", func(t *testing.T) {
+		require.Equal(t, LargePreimageIdentifier{Claimant: claimant, UUID: big.NewInt(0)}.Key(), LargePreimageIdentifier{Claimant: claimant}.Key())
+	})
+
+	t.Run("MetaDataEmbedsIdentifier", func(t *testing.T) {
+		meta := LargePreimageMetaData{LargePreimageIdentifier: ident, ClaimedSize: 5000}
+		require.Equal(t, claimant, meta.Claimant)
+		require.Equal(t, ident.Key(), meta.Key())
+	})
+}
+
+func TestLargePreimageMetaData_Equal(t *testing.T) {
+	base := LargePreimageMetaData{
+		LargePreimageIdentifier: LargePreimageIdentifier{Claimant: common.Address{0xaa}, UUID: big.NewInt(1111)},
+		Timestamp:               1234,
+		PartOffset:              32,
+		ClaimedSize:             5000,
+		BlocksProcessed:         37,
+		BytesProcessed:          5000,
+	}
+	tests := []struct {
+		name     string
+		modify   func(m *LargePreimageMetaData)
+		expected bool
+	}{
+		{name: "Same", modify: func(m *LargePreimageMetaData) {}, expected: true},
+		{name: "SameUUIDValue", modify: func(m *LargePreimageMetaData) { m.UUID = big.NewInt(1111) }, expected: true},
+		{name: "DifferentClaimant", modify: func(m *LargePreimageMetaData) { m.Claimant = common.Address{0xbb} }},
+		{name: "DifferentUUID", modify: func(m *LargePreimageMetaData) { m.UUID = big.NewInt(2222) }},
+		{name: "NilUUID", modify: func(m *LargePreimageMetaData) { m.UUID = nil }},
+		{name: "DifferentTimestamp", modify: func(m *LargePreimageMetaData) { m.Timestamp = 1 }},
+		{name: "DifferentPartOffset", modify: func(m *LargePreimageMetaData) { m.PartOffset = 1 }},
+		{name: "DifferentClaimedSize", modify: func(m *LargePreimageMetaData) { m.ClaimedSize = 1 }},
+		{name: "DifferentBlocksProcessed", modify: func(m *LargePreimageMetaData) { m.BlocksProcessed = 1 }},
+		{name: "DifferentBytesProcessed", modify: func(m *LargePreimageMetaData) { m.BytesProcessed = 1 }},
+		{name: "DifferentCountered", modify: func(m *LargePreimageMetaData) { m.Countered = true }},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			other := base
+			test.modify(&other)
+			require.Equal(t, test.expected, base.Equal(other))
+			require.Equal(t, test.expected, other.Equal(base))
+		})
+	}
+}
+
+func TestSortLargePreimageMetaData(t *testing.T) {
+	proposals := testProposals()
+	// A second proposal from the first claimant with a lower uuid.
+	extra := LargePreimageMetaData{LargePreimageIdentifier: LargePreimageIdentifier{Claimant: proposals[0].Claimant, UUID: big.NewInt(1)}}
+	expected := []LargePreimageMetaData{extra, proposals[0], proposals[1], proposals[2]}
+
+	actual := []LargePreimageMetaData{proposals[2], proposals[0], extra, proposals[1]}
+	SortLargePreimageMetaData(actual)
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		require.Truef(t, expected[i].Equal(actual[i]), "proposal %v differs", i)
+	}
+}
+
+func TestNewPreimageOracleDataFromProposal(t *testing.T) {
+	meta := LargePreimageMetaData{
+		LargePreimageIdentifier: LargePreimageIdentifier{Claimant: common.Address{0xaa}, UUID: big.NewInt(1111)},
+		Timestamp:               1234,
+		PartOffset:              32,
+		ClaimedSize:             5000,
+		BlocksProcessed:         37,
+		BytesProcessed:          5000,
+	}
+	data := NewPreimageOracleDataFromProposal(meta)
+	require.False(t, data.IsLocal)
+	require.Nil(t, data.OracleKey)
+	require.Equal(t, uint32(32), data.OracleOffset)
+	require.NoError(t, data.CheckPreimageSize())
+	require.Equal(t, uint64(5000), binary.BigEndian.Uint64(data.OracleData))
+	require.Empty(t, data.GetPreimageWithoutSize())
+}
+
+func TestPreimageOracleContract_ProposalExists(t *testing.T) {
+	tests := []struct {
+		name        string
+		claimedSize uint32
+		expected    bool
+	}{
+		{name: "Exists", claimedSize: 5000, expected: true},
+		{name: "NotInitialized", claimedSize: 0, expected: false},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			stubRpc, oracle := setupPreimageOracleTest(t)
+			claimant := common.Address{0xaa}
+			uuid := big.NewInt(4444)
+			block := batching.BlockByNumber(223)
+
+			var meta metadata
+			binary.BigEndian.PutUint32(meta[12:16], test.claimedSize)
+			stubRpc.SetResponse(oracleAddr, methodProposalMetadata, block, []interface{}{claimant, uuid}, []interface{}{meta})
+
+			exists, err := oracle.ProposalExists(context.Background(), block, LargePreimageIdentifier{Claimant: claimant, UUID: uuid})
+			require.NoError(t, err)
+			require.Equal(t, test.expected, exists)
+		})
+	}
+}
+
+func TestPreimageOracleContract_IsProposalComplete(t *testing.T) {
+	tests := []struct {
+		name           string
+		claimedSize    uint32
+		bytesProcessed uint32
+		expected       bool
+	}{
+		{name: "Complete", claimedSize: 5000, bytesProcessed: 5000, expected: true},
+		{name: "Incomplete", claimedSize: 5000, bytesProcessed: 4896, expected: false},
+		{name: "NoBytesProcessed", claimedSize: 5000, bytesProcessed: 0, expected: false},
+		{name: "NotInitialized", claimedSize: 0, bytesProcessed: 0, expected: false},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			stubRpc, oracle := setupPreimageOracleTest(t)
+			claimant := common.Address{0xaa}
+			uuid := big.NewInt(4444)
+			block := batching.BlockByNumber(223)
+
+			var meta metadata
+			binary.BigEndian.PutUint32(meta[12:16], test.claimedSize)
+			binary.BigEndian.PutUint32(meta[20:24], test.bytesProcessed)
+			stubRpc.SetResponse(oracleAddr, methodProposalMetadata, block, []interface{}{claimant, uuid}, []interface{}{meta})
+
+			complete, err := oracle.IsProposalComplete(context.Background(), block, LargePreimageIdentifier{Claimant: claimant, UUID: uuid})
+			require.NoError(t, err)
+			require.Equal(t, test.expected, complete)
+		})
+	}
+}
+
+func TestPreimageOracleContract_IsProposalSqueezed(t *testing.T) {
+	tests := []struct {
+		name       string
+		claimSize  uint32
+		timestamp  uint64
+		partLoaded bool
+		expected   bool
+	}{
+		{name: "Squeezed", claimSize: 5000, timestamp: 1234, partLoaded: true, expected: true},
+		{name: "NotSqueezed", claimSize: 5000, timestamp: 1234, partLoaded: false, expected: false},
+		{name: "NotFinalized", claimSize: 5000, timestamp: 0, expected: false},
+		{name: "NotInitialized", claimSize: 0, timestamp: 0, expected: false},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			stubRpc, oracle := setupPreimageOracleTest(t)
+			claimant := common.Address{0xaa}
+			uuid := big.NewInt(4444)
+			block := batching.BlockByNumber(223)
+			digest := common.Hash{0xde, 0xad}
+			partOffset := uint32(40)
+
+			var meta metadata
+			binary.BigEndian.PutUint64(meta[0:8], test.timestamp)
+			binary.BigEndian.PutUint32(meta[8:12], partOffset)
+			binary.BigEndian.PutUint32(meta[12:16], test.claimSize)
+			binary.BigEndian.PutUint32(meta[20:24], test.claimSize)
+			stubRpc.SetResponse(oracleAddr, methodProposalMetadata, block, []interface{}{claimant, uuid}, []interface{}{meta})
+			stubRpc.SetResponse(oracleAddr, methodPreimagePartOk, block, []interface{}{digest, big.NewInt(int64(partOffset))}, []interface{}{test.partLoaded})
+
+			squeezed, err := oracle.IsProposalSqueezed(context.Background(), block, LargePreimageIdentifier{Claimant: claimant, UUID: uuid}, digest)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, squeezed)
+		})
+	}
+}
+
+func TestPreimageOracleContract_GetProposalTreeRoot(t *testing.T) {
+	stubRpc, oracle := setupPreimageOracleTest(t)
+	claimant := common.Address{0xaa}
+	uuid := big.NewInt(4444)
+	block := batching.BlockByNumber(223)
+	expected := common.Hash{0x12, 0x34}
+	stubRpc.SetResponse(oracleAddr, methodGetTreeRootLPP, block, []interface{}{claimant, uuid}, []interface{}{expected})
+
+	root, err := oracle.GetProposalTreeRoot(context.Background(), block, LargePreimageIdentifier{Claimant: claimant, UUID: uuid})
+	require.NoError(t, err)
+	require.Equal(t, expected, root)
+}
+
+func TestPreimageOracleContract_GetInputDataBlocks(t *testing.T) {
+	stubRpc, oracle := setupPreimageOracleTest(t)
+	claimant := common.Address{0xaa}
+	uuid := big.NewInt(4444)
+	block := batching.BlockByNumber(223)
+	expected := []uint64{60, 80, 100}
+	stubRpc.SetResponse(oracleAddr, methodProposalBlocksLen, block, []interface{}{claimant, uuid}, []interface{}{big.NewInt(int64(len(expected)))})
+	for i, blockNum := range expected {
+		stubRpc.SetResponse(oracleAddr, methodProposalBlocks, block, []interface{}{claimant, uuid, big.NewInt(int64(i))}, []interface{}{blockNum})
+	}
+
+	blocks, err := oracle.GetInputDataBlocks(context.Background(), block, LargePreimageIdentifier{Claimant: claimant, UUID: uuid})
+	require.NoError(t, err)
+	require.Equal(t, expected, blocks)
+}
+
+func TestPreimageOracleContract_DecodeInputData(t *testing.T) {
+	_, oracle := setupPreimageOracleTest(t)
+	uuid := big.NewInt(1234)
+	leaves := []Leaf{
+		{Input: bytes.Repeat([]byte{0x01}, matrix.LeafSize), Index: big.NewInt(0), StateCommitment: common.Hash{0xaa}},
+		{Input: []byte{0x02, 0x03}, Index: big.NewInt(1), StateCommitment: common.Hash{0xbb}},
+	}
+
+	for _, finalize := range []bool{true, false} {
+		finalize := finalize
+		t.Run(fmt.Sprintf("Finalize-%v", finalize), func(t *testing.T) {
+			txs, err := oracle.AddLeaves(uuid, 0, leaves, finalize, DefaultMaxLeavesPerTx)
+			require.NoError(t, err)
+			require.Len(t, txs, 1)
+
+			actualUUID, actual, err := oracle.DecodeInputData(txs[0].TxData)
+			require.NoError(t, err)
+			require.Equal(t, uuid, actualUUID)
+			require.Equal(t, InputData{
+				Input:       append(leaves[0].Input, leaves[1].Input...),
+				Commitments: []common.Hash{leaves[0].StateCommitment, leaves[1].StateCommitment},
+				Finalize:    finalize,
+			}, actual)
+		})
+	}
+
+	t.Run("WrongMethod", func(t *testing.T) {
+		tx, err := oracle.InitLargePreimage(uuid, 0, 1000)
+		require.NoError(t, err)
+		_, _, err = oracle.DecodeInputData(tx.TxData)
+		require.ErrorIs(t, err, ErrInvalidAddLeavesCall)
+	})
+
+	t.Run("UnknownMethod", func(t *testing.T) {
+		_, _, err := oracle.DecodeInputData([]byte{1, 2, 3, 4})
+		require.ErrorIs(t, err, batching.ErrUnknownMethod)
+	})
+}
+
+func TestPreimageOracleContract_GetActivePreimages(t *testing.T) {
+	stubRpc, oracle := setupPreimageOracleTest(t)
+	blockHash := common.Hash{0xaa}
+	expected := setupActivePreimages(stubRpc, batching.BlockByHash(blockHash))
+
+	preimages, err := oracle.GetActivePreimages(context.Background(), blockHash)
+	require.NoError(t, err)
+	require.Equal(t, expected, preimages)
+}
+
+func TestPreimageOracleContract_CountActivePreimages(t *testing.T) {
+	t.Run("WithProposals", func(t *testing.T) {
+		stubRpc, oracle := setupPreimageOracleTest(t)
+		block := batching.BlockByHash(common.Hash{0xaa})
+		setupActivePreimages(stubRpc, block)
+
+		counts, err := oracle.CountActivePreimages(context.Background(), block)
+		require.NoError(t, err)
+		require.Equal(t, gameTypes.ActivePreimageCounts{Countered: 1, Uncountered: 2}, counts)
+	})
+
+	t.Run("DuplicateProposals", func(t *testing.T) {
+		stubRpc, oracle := setupPreimageOracleTest(t)
+		block := batching.BlockByHash(common.Hash{0xaa})
+		proposals := testProposals()
+		// Initializing an existing proposal again adds a duplicate entry to the oracle's list of proposals.
+		setupProposals(stubRpc, block, append(proposals, proposals[0], proposals[2]))
+
+		counts, err := oracle.CountActivePreimages(context.Background(), block)
+		require.NoError(t, err)
+		require.Equal(t, gameTypes.ActivePreimageCounts{Countered: 1, Uncountered: 2}, counts)
+	})
+
+	t.Run("NoProposals", func(t *testing.T) {
+		stubRpc, oracle := setupPreimageOracleTest(t)
+		stubRpc.SetResponse(oracleAddr, methodProposalCount, batching.BlockLatest, nil, []interface{}{big.NewInt(0)})
+
+		counts, err := oracle.CountActivePreimages(context.Background(), batching.BlockLatest)
+		require.NoError(t, err)
+		require.Equal(t, gameTypes.ActivePreimageCounts{}, counts)
+	})
+}
+
+func TestPreimageOracleContract_GetActivePreimagesByClaimant(t *testing.T) {
+	stubRpc, oracle := setupPreimageOracleTest(t)
+	block := batching.BlockByHash(common.Hash{0xaa})
+	proposals := testProposals()
+	// Add a second proposal from the first claimant, created after a proposal from a different claimant.
+	extra := LargePreimageMetaData{
+		LargePreimageIdentifier: LargePreimageIdentifier{Claimant: proposals[0].Claimant, UUID: big.NewInt(999)},
+		ClaimedSize:             500,
+		BlocksProcessed:         1,
+		BytesProcessed:          136,
+	}
+	setupProposals(stubRpc, block, append(proposals, extra))
+
+	t.Run("MultipleMatches", func(t *testing.T) {
+		preimages, err := oracle.GetActivePreimagesByClaimant(context.Background(), block, proposals[0].Claimant)
+		require.NoError(t, err)
+		require.Equal(t, []LargePreimageMetaData{proposals[0], extra}, preimages)
+	})
+
+	t.Run("SingleMatch", func(t *testing.T) {
+		preimages, err := oracle.GetActivePreimagesByClaimant(context.Background(), block, proposals[1].Claimant)
+		require.NoError(t, err)
+		require.Equal(t, []LargePreimageMetaData{proposals[1]}, preimages)
+	})
+
+	t.Run("NoMatches", func(t *testing.T) {
+		preimages, err := oracle.GetActivePreimagesByClaimant(context.Background(), block, common.Address{0xff})
+		require.NoError(t, err)
+		require.Empty(t, preimages)
+	})
+
+	t.Run("DuplicateProposals", func(t *testing.T) {
+		stubRpc, oracle := setupPreimageOracleTest(t)
+		setupProposals(stubRpc, block, append(proposals, proposals[0]))
+		preimages, err := oracle.GetActivePreimagesByClaimant(context.Background(), block, proposals[0].Claimant)
+		require.NoError(t, err)
+		require.Equal(t, []LargePreimageMetaData{proposals[0]}, preimages)
+	})
+}
+
+// setupActivePreimages configures stubRpc to return the test proposals at block.
+func setupActivePreimages(stubRpc *batchingTest.AbiBasedRpc, block batching.Block) []LargePreimageMetaData {
+	proposals := testProposals()
+	setupProposals(stubRpc, block, proposals)
+	return proposals
+}
+
+// testProposals returns a set of proposals from different claimants, including a countered proposal.
+func testProposals() []LargePreimageMetaData {
+	return []LargePreimageMetaData{
+		{
+			LargePreimageIdentifier: LargePreimageIdentifier{Claimant: common.Address{0x12}, UUID: big.NewInt(123)},
+			PartOffset:              0,
+			ClaimedSize:             1000,
+			BlocksProcessed:         3,
+			BytesProcessed:          408,
+		},
+		{
+			LargePreimageIdentifier: LargePreimageIdentifier{Claimant: common.Address{0x34}, UUID: big.NewInt(456)},
+			Timestamp:               1234,
+			PartOffset:              8,
+			ClaimedSize:             300,
+			BlocksProcessed:         3,
+			BytesProcessed:          300,
+			Countered:               true,
+		},
+		{
+			LargePreimageIdentifier: LargePreimageIdentifier{Claimant: common.Address{0x56}, UUID: big.NewInt(789)},
+			Timestamp:               5678,
+			ClaimedSize:             200,
+			BlocksProcessed:         2,
+			BytesProcessed:          200,
+		},
+	}
+}
+
+// setupProposals configures stubRpc to return proposals from the oracle at block.
+func setupProposals(stubRpc *batchingTest.AbiBasedRpc, block batching.Block, proposals []LargePreimageMetaData) {
+	stubRpc.SetResponse(oracleAddr, methodProposalCount, block, nil, []interface{}{big.NewInt(int64(len(proposals)))})
+	for i, proposal := range proposals {
+		var meta metadata
+		binary.BigEndian.PutUint64(meta[0:8], proposal.Timestamp)
+		binary.BigEndian.PutUint32(meta[8:12], proposal.PartOffset)
+		binary.BigEndian.PutUint32(meta[12:16], proposal.ClaimedSize)
+		binary.BigEndian.PutUint32(meta[16:20], proposal.BlocksProcessed)
+		binary.BigEndian.PutUint32(meta[20:24], proposal.BytesProcessed)
+		if proposal.Countered {
+			binary.BigEndian.PutUint64(meta[24:32], 1)
+		}
+		stubRpc.SetResponse(oracleAddr, methodProposals, block, []interface{}{big.NewInt(int64(i))}, []interface{}{proposal.Claimant, proposal.UUID})
+		stubRpc.SetResponse(oracleAddr, methodProposalMetadata, block, []interface{}{proposal.Claimant, proposal.UUID}, []interface{}{meta})
+	}
+}
+
+func TestPreimageOracleContract_GetActivePreimages_NoProposals(t *testing.T) {
+	stubRpc, oracle := setupPreimageOracleTest(t)
+	blockHash := common.Hash{0xaa}
+	stubRpc.SetResponse(oracleAddr, methodProposalCount, batching.BlockByHash(blockHash), nil, []interface{}{big.NewInt(0)})
+
+	preimages, err := oracle.GetActivePreimages(context.Background(), blockHash)
+	require.NoError(t, err)
+	require.Empty(t, preimages)
+}
+
+func TestPreimageOracleContract_GetActivePreimages_MalformedMetadata(t *testing.T) {
+	oracleAbi, err := bindings.PreimageOracleMetaData.GetAbi()
+	require.NoError(t, err)
+	// Copy the ABI so proposalMetadata returns an extra value without modifying the shared instance.
+	malformedAbi := *oracleAbi
+	malformedAbi.Methods = make(map[string]abi.Method, len(oracleAbi.Methods))
+	for name, method := range oracleAbi.Methods {
+		malformedAbi.Methods[name] = method
+	}
+	method := malformedAbi.Methods[methodProposalMetadata]
+	method.Outputs = append(abi.Arguments{}, method.Outputs...)
+	method.Outputs = append(method.Outputs, method.Outputs[0])
+	malformedAbi.Methods[methodProposalMetadata] = method
+
+	stubRpc := batchingTest.NewAbiBasedRpc(t, oracleAddr, &malformedAbi)
+	oracle, err := NewPreimageOracleContract(oracleAddr, batching.NewMultiCaller(stubRpc, batching.DefaultBatchSize))
+	require.NoError(t, err)
+	oracle.contract = batching.NewBoundContract(&malformedAbi, oracleAddr)
+
+	blockHash := common.Hash{0xaa}
+	block := batching.BlockByHash(blockHash)
+	claimant := common.Address{0x12}
+	uuid := big.NewInt(123)
+	stubRpc.SetResponse(oracleAddr, methodProposalCount, block, nil, []interface{}{big.NewInt(1)})
+	stubRpc.SetResponse(oracleAddr, methodProposals, block, []interface{}{big.NewInt(0)}, []interface{}{claimant, uuid})
+	stubRpc.SetResponse(oracleAddr, methodProposalMetadata, block, []interface{}{claimant, uuid}, []interface{}{[32]byte{}, [32]byte{}})
+
+	_, err = oracle.GetActivePreimages(context.Background(), blockHash)
+	require.ErrorIs(t, err, ErrMalformedProposalMetadata)
+}
+
+func TestPreimageOracleContract_AbiShared(t *testing.T) {
+	_, oracle1 := setupPreimageOracleTest(t)
+	_, oracle2 := setupPreimageOracleTest(t)
+	expected, err := bindings.PreimageOracleMetaData.GetAbi()
+	require.NoError(t, err)
+	require.Same(t, expected, oracle1.contract.Call(methodChallengePeriod).Abi)
+	require.Same(t, expected, oracle2.contract.Call(methodChallengePeriod).Abi)
+}
+
+func setupPreimageOracleTest(t *testing.T) (*batchingTest.AbiBasedRpc, *PreimageOracleContract) {
+	oracleAbi, err := bindings.PreimageOracleMetaData.GetAbi()
+	require.NoError(t, err)
+
+	stubRpc := batchingTest.NewAbiBasedRpc(t, oracleAddr, oracleAbi)
+	oracleContract, err := NewPreimageOracleContract(oracleAddr, batching.NewMultiCaller(stubRpc, batching.DefaultBatchSize))
+	require.NoError(t, err)
+	return stubRpc, oracleContract
+}
+
+func TestPreimageOracleContract_ChallengePeriod(t *testing.T) {
+	stubRpc, oracle := setupPreimageOracleTest(t)
+	stubRpc.SetResponse(oracleAddr, methodChallengePeriod, batching.BlockLatest, nil, []interface{}{big.NewInt(123)})
+
+	challengePeriod, err := oracle.ChallengePeriod(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(123), challengePeriod)
+}
+
+func TestPreimageOracleContract_ChallengePeriodCached(t *testing.T) {
+	oracleAbi, err := bindings.PreimageOracleMetaData.GetAbi()
+	require.NoError(t, err)
+	stubRpc := batchingTest.NewAbiBasedRpc(t, oracleAddr, oracleAbi)
+	counter := &callCountingRpc{AbiBasedRpc: stubRpc}
+	oracle, err := NewPreimageOracleContract(oracleAddr, batching.NewMultiCaller(counter, batching.DefaultBatchSize))
+	require.NoError(t, err)
+	stubRpc.SetResponse(oracleAddr, methodChallengePeriod, batching.BlockLatest, nil, []interface{}{big.NewInt(123)})
+
+	for i := 0; i < 3; i++ {
+		challengePeriod, err := oracle.ChallengePeriod(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(123), challengePeriod)
+	}
+	require.Equal(t, 1, counter.calls)
+}
+
+func TestPreimageOracleContract_MaxProposalSize(t *testing.T) {
+	oracleAbi, err := bindings.PreimageOracleMetaData.GetAbi()
+	require.NoError(t, err)
+	stubRpc := batchingTest.NewAbiBasedRpc(t, oracleAddr, oracleAbi)
+	counter := &callCountingRpc{AbiBasedRpc: stubRpc}
+	oracle, err := NewPreimageOracleContract(oracleAddr, batching.NewMultiCaller(counter, batching.DefaultBatchSize))
+	require.NoError(t, err)
+	stubRpc.SetResponse(oracleAddr, methodMaxLeafCount, batching.BlockLatest, nil, []interface{}{big.NewInt(65535)})
+
+	for i := 0; i < 3; i++ {
+		size, err := oracle.MaxProposalSize(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(65535*matrix.LeafSize-1), size)
+	}
+	require.Equal(t, 1, counter.calls)
+}
+
+func TestPreimageOracleContract_ChallengePeriodNotCachedOnError(t *testing.T) {
+	oracleAbi, err := bindings.PreimageOracleMetaData.GetAbi()
+	require.NoError(t, err)
+	stubRpc := batchingTest.NewAbiBasedRpc(t, oracleAddr, oracleAbi)
+	oracle, err := NewPreimageOracleContract(oracleAddr, batching.NewMultiCaller(&failingRpc{AbiBasedRpc: stubRpc, failures: 1}, batching.DefaultBatchSize))
+	require.NoError(t, err)
+	stubRpc.SetResponse(oracleAddr, methodChallengePeriod, batching.BlockLatest, nil, []interface{}{big.NewInt(123)})
+
+	_, err = oracle.ChallengePeriod(context.Background())
+	require.Error(t, err)
+
+	challengePeriod, err := oracle.ChallengePeriod(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(123), challengePeriod)
+}
+
+// callCountingRpc counts the number of single call requests made.
+type callCountingRpc struct {
+	*batchingTest.AbiBasedRpc
+	calls int
+}
+
+func (r *callCountingRpc) CallContext(ctx context.Context, out interface{}, method string, args ...interface{}) error {
+	r.calls++
+	return r.AbiBasedRpc.CallContext(ctx, out, method, args...)
+}
+
+// failingRpc fails the first failures single call requests before delegating to the underlying rpc.
+type failingRpc struct {
+	*batchingTest.AbiBasedRpc
+	failures int
+}
+
+func (r *failingRpc) CallContext(ctx context.Context, out interface{}, method string, args ...interface{}) error {
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("call failed")
+	}
+	return r.AbiBasedRpc.CallContext(ctx, out, method, args...)
+}
+
+func TestPreimageOracleContract_Squeeze(t *testing.T) {
+	stubRpc, oracle := setupPreimageOracleTest(t)
+
+	claimant := common.Address{0x12}
+	uuid := big.NewInt(123)
+	stateMatrix := matrix.StateSnapshot{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24}
+	preState := Leaf{
+		Input:           make([]byte, matrix.LeafSize),
+		Index:           big.NewInt(23),
+		StateCommitment: common.Hash{0x44},
+	}
+	preStateProof := merkle.Proof{{0x00}, {0x01}, {0x02}}
+	postState := Leaf{
+		Input:           []byte{0x12},
+		Index:           big.NewInt(24),
+		StateCommitment: common.Hash{0x55},
+	}
+	postStateProof := merkle.Proof{{0x03}, {0x04}, {0x05}}
+	stubRpc.SetResponse(oracleAddr, methodSqueezeLPP, batching.BlockLatest, []interface{}{
+		claimant,
+		uuid,
+		bindings.LibKeccakStateMatrix{State: stateMatrix},
+		toPreimageOracleLeaf(preState),
+		toProofArray(preStateProof),
+		toPreimageOracleLeaf(postState),
+		toProofArray(postStateProof),
+	}, nil)
+
+	tx, err := oracle.Squeeze(LargePreimageIdentifier{Claimant: claimant, UUID: uuid}, stateMatrix, preState, preStateProof, postState, postStateProof)
+	require.NoError(t, err)
+	stubRpc.VerifyTxCandidate(tx)
+}
+
+func TestPreimageOracleContract_ChallengeTx(t *testing.T) {
+	claimant := common.Address{0x12}
+	uuid := big.NewInt(123)
+
+	t.Run("First", func(t *testing.T) {
+		stubRpc, oracle := setupPreimageOracleTest(t)
+		challenge := ChallengeParams{
+			Poststate: Leaf{
+				Input:           make([]byte, matrix.LeafSize),
+				Index:           big.NewInt(0),
+				StateCommitment: common.Hash{0x55},
+			},
+			PoststateProof: merkle.Proof{{0x03}, {0x04}, {0x05}},
+		}
+		stubRpc.SetResponse(oracleAddr, methodChallengeFirstLPP, batching.BlockLatest, []interface{}{
+			claimant,
+			uuid,
+			toPreimageOracleLeaf(challenge.Poststate),
+			toProofArray(challenge.PoststateProof),
+		}, nil)
+
+		tx, err := oracle.ChallengeTx(LargePreimageIdentifier{Claimant: claimant, UUID: uuid}, challenge)
+		require.NoError(t, err)
+		stubRpc.VerifyTxCandidate(tx)
+	})
+
+	t.Run("Subsequent", func(t *testing.T) {
+		stubRpc, oracle := setupPreimageOracleTest(t)
+		challenge := ChallengeParams{
+			StateMatrix: matrix.StateSnapshot{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24},
+			Prestate: Leaf{
+				Input:           make([]byte, matrix.LeafSize),
+				Index:           big.NewInt(23),
+				StateCommitment: common.Hash{0x44},
+			},
+			PrestateProof: merkle.Proof{{0x00}, {0x01}, {0x02}},
+			Poststate: Leaf{
+				Input:           make([]byte, matrix.LeafSize),
+				Index:           big.NewInt(24),
+				StateCommitment: common.Hash{0x55},
+			},
+			PoststateProof: merkle.Proof{{0x03}, {0x04}, {0x05}},
+		}
+		stubRpc.SetResponse(oracleAddr, methodChallengeLPP, batching.BlockLatest, []interface{}{
+			claimant,
+			uuid,
+			bindings.LibKeccakStateMatrix{State: challenge.StateMatrix},
+			toPreimageOracleLeaf(challenge.Prestate),
+			toProofArray(challenge.PrestateProof),
+			toPreimageOracleLeaf(challenge.Poststate),
+			toProofArray(challenge.PoststateProof),
+		}, nil)
+
+		tx, err := oracle.ChallengeTx(LargePreimageIdentifier{Claimant: claimant, UUID: uuid}, challenge)
+		require.NoError(t, err)
+		stubRpc.VerifyTxCandidate(tx)
+	})
+
+	t.Run("MissingPoststateIndex", func(t *testing.T) {
+		_, oracle := setupPreimageOracleTest(t)
+		challenge := ChallengeParams{
+			Poststate: Leaf{Input: make([]byte, matrix.LeafSize), StateCommitment: common.Hash{0x55}},
+		}
+		_, err := oracle.ChallengeTx(LargePreimageIdentifier{Claimant: claimant, UUID: uuid}, challenge)
+		require.ErrorIs(t, err, ErrMissingLeafIndex)
+	})
+
+	t.Run("MissingPrestateIndex", func(t *testing.T) {
+		_, oracle := setupPreimageOracleTest(t)
+		challenge := ChallengeParams{
+			Prestate:  Leaf{Input: make([]byte, matrix.LeafSize), StateCommitment: common.Hash{0x44}},
+			Poststate: Leaf{Input: make([]byte, matrix.LeafSize), Index: big.NewInt(24), StateCommitment: common.Hash{0x55}},
+		}
+		_, err := oracle.ChallengeTx(LargePreimageIdentifier{Claimant: claimant, UUID: uuid}, challenge)
+		require.ErrorIs(t, err, ErrMissingLeafIndex)
+	})
+}
+
+func TestMerkleProof(t *testing.T) {
+	for _, count := range []int{1, 2, 3, 5, 8, 17} {
+		count := count
+		t.Run(fmt.Sprintf("Leaves-%v", count), func(t *testing.T) {
+			leaves := make([]Leaf, count)
+			for i := range leaves {
+				leaves[i] = Leaf{
+					Input:           bytes.Repeat([]byte{byte(i)}, matrix.LeafSize),
+					Index:           big.NewInt(int64(i)),
+					StateCommitment: common.Hash{byte(i), 0xcc},
+				}
+			}
+			root, err := MerkleRoot(leaves)
+			require.NoError(t, err)
+			for i, leaf := range leaves {
+				nodes, err := MerkleProof(leaves, i)
+				require.NoError(t, err)
+				require.Len(t, nodes, merkle.BinaryMerkleTreeDepth)
+				var proof merkle.Proof
+				for j, node := range nodes {
+					proof[j] = node
+				}
+				require.True(t, merkle.Verify(root, uint64(i), leaf.Hash(), proof), "proof for leaf %v should be valid", i)
+			}
+		})
+	}
+
+	t.Run("IndexOutOfBounds", func(t *testing.T) {
+		leaves := []Leaf{{Input: []byte{1}, Index: big.NewInt(0)}}
+		_, err := MerkleProof(leaves, 1)
+		require.ErrorIs(t, err, merkle.ErrIndexOutOfBounds)
+		_, err = MerkleProof(leaves, -1)
+		require.ErrorIs(t, err, merkle.ErrIndexOutOfBounds)
+		_, err = MerkleProof(nil, 0)
+		require.ErrorIs(t, err, merkle.ErrIndexOutOfBounds)
+	})
+}
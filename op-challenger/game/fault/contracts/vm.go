@@ -32,9 +32,18 @@ func NewVMContract(addr common.Address, caller *batching.MultiCaller) (*VMContra
 }
 
 func (c *VMContract) Oracle(ctx context.Context) (*PreimageOracleContract, error) {
+	addr, err := c.OracleAddr(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewPreimageOracleContract(addr, c.multiCaller)
+}
+
+// OracleAddr returns the address of the preimage oracle used by the VM.
+func (c *VMContract) OracleAddr(ctx context.Context) (common.Address, error) {
 	results, err := c.multiCaller.SingleCall(ctx, batching.BlockLatest, c.contract.Call(methodOracle))
 	if err != nil {
-		return nil, fmt.Errorf("failed to load oracle address: %w", err)
+		return common.Address{}, fmt.Errorf("failed to load oracle address: %w", err)
 	}
-	return NewPreimageOracleContract(results.GetAddress(0), c.multiCaller)
+	return results.GetAddress(0), nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/contracts"
 	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/preimages"
 	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/responder"
 	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/types"
@@ -35,6 +36,7 @@ type GameContract interface {
 	ClaimLoader
 	GetStatus(ctx context.Context) (gameTypes.GameStatus, error)
 	GetMaxGameDepth(ctx context.Context) (types.Depth, error)
+	GetOracleAddr(ctx context.Context) (common.Address, error)
 }
 
 type resourceCreator func(ctx context.Context, logger log.Logger, gameDepth types.Depth, dir string) (types.TraceAccessor, error)
@@ -46,6 +48,10 @@ func NewGamePlayer(
 	dir string,
 	addr common.Address,
 	txMgr txmgr.TxManager,
+	l1Head preimages.L1HeaderSource,
+	uploads *preimages.UploadScheduler,
+	preimageTxMgr txmgr.TxManager,
+	oracles *contracts.OracleRegistry,
 	loader GameContract,
 	validators []Validator,
 	creator resourceCreator,
@@ -81,9 +87,14 @@ func NewGamePlayer(
 		return nil, fmt.Errorf("failed to create trace accessor: %w", err)
 	}
 
+	oracle, err := oracles.GameOracle(ctx, loader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the preimage oracle: %w", err)
+	}
+
 	direct := preimages.NewDirectPreimageUploader(logger, txMgr, loader)
-	large := preimages.NewLargePreimageUploader(logger, txMgr, loader)
-	uploader := preimages.NewSplitPreimageUploader(direct, large)
+	large := preimages.NewLargePreimageUploader(logger, m, preimageTxMgr, l1Head, oracle)
+	uploader := preimages.NewSplitPreimageUploader(direct, uploads.Limit(large))
 
 	responder, err := responder.NewFaultResponder(logger, txMgr, loader, uploader)
 	if err != nil {
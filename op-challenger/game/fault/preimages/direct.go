@@ -6,6 +6,7 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/types"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
+	"github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -18,39 +19,40 @@ type DirectPreimageUploader struct {
 	log log.Logger
 
 	txMgr    txmgr.TxManager
-	contract PreimageOracleContract
+	contract PreimageGameContract
 }
 
-func NewDirectPreimageUploader(logger log.Logger, txMgr txmgr.TxManager, contract PreimageOracleContract) *DirectPreimageUploader {
+func NewDirectPreimageUploader(logger log.Logger, txMgr txmgr.TxManager, contract PreimageGameContract) *DirectPreimageUploader {
 	return &DirectPreimageUploader{logger, txMgr, contract}
 }
 
-func (d *DirectPreimageUploader) UploadPreimage(ctx context.Context, claimIdx uint64, data *types.PreimageOracleData) error {
+func (d *DirectPreimageUploader) UploadPreimage(ctx context.Context, claimIdx uint64, data *types.PreimageOracleData) ([]common.Hash, error) {
 	if data == nil {
-		return ErrNilPreimageData
+		return nil, ErrNilPreimageData
 	}
 	d.log.Info("Updating oracle data", "key", data.OracleKey)
 	candidate, err := d.contract.UpdateOracleTx(ctx, claimIdx, data)
 	if err != nil {
-		return fmt.Errorf("failed to create pre-image oracle tx: %w", err)
+		return nil, fmt.Errorf("failed to create pre-image oracle tx: %w", err)
 	}
-	if err := d.sendTxAndWait(ctx, candidate); err != nil {
-		return fmt.Errorf("failed to populate pre-image oracle: %w", err)
+	txHash, err := d.sendTxAndWait(ctx, candidate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to populate pre-image oracle: %w", err)
 	}
-	return nil
+	return []common.Hash{txHash}, nil
 }
 
 // sendTxAndWait sends a transaction through the [txmgr] and waits for a receipt.
 // This sets the tx GasLimit to 0, performing gas estimation online through the [txmgr].
-func (d *DirectPreimageUploader) sendTxAndWait(ctx context.Context, candidate txmgr.TxCandidate) error {
+func (d *DirectPreimageUploader) sendTxAndWait(ctx context.Context, candidate txmgr.TxCandidate) (common.Hash, error) {
 	receipt, err := d.txMgr.Send(ctx, candidate)
 	if err != nil {
-		return err
+		return common.Hash{}, err
 	}
 	if receipt.Status == ethtypes.ReceiptStatusFailed {
 		d.log.Error("DirectPreimageUploader tx successfully published but reverted", "tx_hash", receipt.TxHash)
 	} else {
 		d.log.Debug("DirectPreimageUploader tx successfully published", "tx_hash", receipt.TxHash)
 	}
-	return nil
+	return receipt.TxHash, nil
 }
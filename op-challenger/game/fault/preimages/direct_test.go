@@ -23,7 +23,7 @@ func TestDirectPreimageUploader_UploadPreimage(t *testing.T) {
 	t.Run("UpdateOracleTxFails", func(t *testing.T) {
 		oracle, txMgr, contract := newTestDirectPreimageUploader(t)
 		contract.uploadFails = true
-		err := oracle.UploadPreimage(context.Background(), 0, &types.PreimageOracleData{})
+		_, err := oracle.UploadPreimage(context.Background(), 0, &types.PreimageOracleData{})
 		require.ErrorIs(t, err, mockUpdateOracleTxError)
 		require.Equal(t, 1, contract.updates)
 		require.Equal(t, 0, txMgr.sends) // verify that the tx was not sent
@@ -32,7 +32,7 @@ func TestDirectPreimageUploader_UploadPreimage(t *testing.T) {
 	t.Run("SendFails", func(t *testing.T) {
 		oracle, txMgr, contract := newTestDirectPreimageUploader(t)
 		txMgr.sendFails = true
-		err := oracle.UploadPreimage(context.Background(), 0, &types.PreimageOracleData{})
+		_, err := oracle.UploadPreimage(context.Background(), 0, &types.PreimageOracleData{})
 		require.ErrorIs(t, err, mockTxMgrSendError)
 		require.Equal(t, 1, contract.updates)
 		require.Equal(t, 1, txMgr.sends)
@@ -40,15 +40,16 @@ func TestDirectPreimageUploader_UploadPreimage(t *testing.T) {
 
 	t.Run("NilPreimageData", func(t *testing.T) {
 		oracle, _, _ := newTestDirectPreimageUploader(t)
-		err := oracle.UploadPreimage(context.Background(), 0, nil)
+		_, err := oracle.UploadPreimage(context.Background(), 0, nil)
 		require.ErrorIs(t, err, ErrNilPreimageData)
 	})
 
 	t.Run("Success", func(t *testing.T) {
-		oracle, _, contract := newTestDirectPreimageUploader(t)
-		err := oracle.UploadPreimage(context.Background(), 0, &types.PreimageOracleData{})
+		oracle, txMgr, contract := newTestDirectPreimageUploader(t)
+		txHashes, err := oracle.UploadPreimage(context.Background(), 0, &types.PreimageOracleData{})
 		require.NoError(t, err)
 		require.Equal(t, 1, contract.updates)
+		require.Equal(t, txMgr.txHashes, txHashes)
 	})
 }
 
@@ -56,7 +57,7 @@ func TestDirectPreimageUploader_SendTxAndWait(t *testing.T) {
 	t.Run("SendFails", func(t *testing.T) {
 		oracle, txMgr, _ := newTestDirectPreimageUploader(t)
 		txMgr.sendFails = true
-		err := oracle.sendTxAndWait(context.Background(), txmgr.TxCandidate{})
+		_, err := oracle.sendTxAndWait(context.Background(), txmgr.TxCandidate{})
 		require.ErrorIs(t, err, mockTxMgrSendError)
 		require.Equal(t, 1, txMgr.sends)
 	})
@@ -64,32 +65,32 @@ func TestDirectPreimageUploader_SendTxAndWait(t *testing.T) {
 	t.Run("ReceiptStatusFailed", func(t *testing.T) {
 		oracle, txMgr, _ := newTestDirectPreimageUploader(t)
 		txMgr.statusFail = true
-		err := oracle.sendTxAndWait(context.Background(), txmgr.TxCandidate{})
+		_, err := oracle.sendTxAndWait(context.Background(), txmgr.TxCandidate{})
 		require.NoError(t, err)
 		require.Equal(t, 1, txMgr.sends)
 	})
 
 	t.Run("Success", func(t *testing.T) {
 		oracle, txMgr, _ := newTestDirectPreimageUploader(t)
-		err := oracle.sendTxAndWait(context.Background(), txmgr.TxCandidate{})
+		_, err := oracle.sendTxAndWait(context.Background(), txmgr.TxCandidate{})
 		require.NoError(t, err)
 		require.Equal(t, 1, txMgr.sends)
 	})
 }
 
-func newTestDirectPreimageUploader(t *testing.T) (*DirectPreimageUploader, *mockTxMgr, *mockPreimageOracleContract) {
+func newTestDirectPreimageUploader(t *testing.T) (*DirectPreimageUploader, *mockTxMgr, *mockPreimageGameContract) {
 	logger := testlog.Logger(t, log.LvlError)
 	txMgr := &mockTxMgr{}
-	contract := &mockPreimageOracleContract{}
+	contract := &mockPreimageGameContract{}
 	return NewDirectPreimageUploader(logger, txMgr, contract), txMgr, contract
 }
 
-type mockPreimageOracleContract struct {
+type mockPreimageGameContract struct {
 	updates     int
 	uploadFails bool
 }
 
-func (s *mockPreimageOracleContract) UpdateOracleTx(_ context.Context, _ uint64, _ *types.PreimageOracleData) (txmgr.TxCandidate, error) {
+func (s *mockPreimageGameContract) UpdateOracleTx(_ context.Context, _ uint64, _ *types.PreimageOracleData) (txmgr.TxCandidate, error) {
 	s.updates++
 	if s.uploadFails {
 		return txmgr.TxCandidate{}, mockUpdateOracleTxError
@@ -101,17 +102,25 @@ type mockTxMgr struct {
 	sends      int
 	sendFails  bool
 	statusFail bool
+	// failAt is the 1-based index of the send that fails. Zero if no sends fail.
+	failAt int
+	// txHashes records the hash of each published tx, in the order they were sent.
+	txHashes   []common.Hash
+	candidates []txmgr.TxCandidate
 }
 
-func (s *mockTxMgr) Send(_ context.Context, _ txmgr.TxCandidate) (*ethtypes.Receipt, error) {
+func (s *mockTxMgr) Send(_ context.Context, candidate txmgr.TxCandidate) (*ethtypes.Receipt, error) {
 	s.sends++
-	if s.sendFails {
+	s.candidates = append(s.candidates, candidate)
+	if s.sendFails || s.sends == s.failAt {
 		return nil, mockTxMgrSendError
 	}
+	txHash := common.Hash{0xcc, byte(s.sends)}
+	s.txHashes = append(s.txHashes, txHash)
 	if s.statusFail {
-		return &ethtypes.Receipt{Status: ethtypes.ReceiptStatusFailed}, nil
+		return &ethtypes.Receipt{Status: ethtypes.ReceiptStatusFailed, TxHash: txHash}, nil
 	}
-	return &ethtypes.Receipt{}, nil
+	return &ethtypes.Receipt{Status: ethtypes.ReceiptStatusSuccessful, TxHash: txHash}, nil
 }
 
 func (s *mockTxMgr) BlockNumber(_ context.Context) (uint64, error) { return 0, nil }
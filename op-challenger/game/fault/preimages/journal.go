@@ -0,0 +1,91 @@
+package preimages
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-service/ioutil"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// UploadJournal persists the progress of large preimage uploads to disk so the progress of an interrupted upload
+// is known after a restart.
+// The journal is a JSON file, gzip compressed if the path ends in .gz, mapping the hash of the preimage key to the
+// progress of its upload.
+type UploadJournal struct {
+	path    string
+	lock    sync.Mutex
+	entries map[common.Hash]JournalEntry
+}
+
+// JournalEntry is the recorded progress of a single large preimage upload.
+type JournalEntry struct {
+	UUID *big.Int `json:"uuid"`
+	// LeavesAdded is the number of leaves, starting from the first leaf, that have been added to the proposal.
+	LeavesAdded uint64 `json:"leavesAdded"`
+}
+
+// NewUploadJournal creates a journal stored at path, loading any existing entries.
+func NewUploadJournal(path string) (*UploadJournal, error) {
+	j := &UploadJournal{
+		path:    path,
+		entries: make(map[common.Hash]JournalEntry),
+	}
+	file, err := ioutil.OpenDecompressed(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return j, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to open upload journal (%v): %w", path, err)
+	}
+	defer file.Close()
+	if err := json.NewDecoder(file).Decode(&j.entries); err != nil {
+		return nil, fmt.Errorf("invalid upload journal (%v): %w", path, err)
+	}
+	return j, nil
+}
+
+// Get returns the recorded progress of the upload for the preimage with the specified key hash.
+func (j *UploadJournal) Get(key common.Hash) (JournalEntry, bool) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	entry, ok := j.entries[key]
+	return entry, ok
+}
+
+// Record stores the progress of the upload for the preimage with the specified key hash and writes the journal to disk.
+func (j *UploadJournal) Record(key common.Hash, entry JournalEntry) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.entries[key] = entry
+	return j.write()
+}
+
+// Remove deletes the progress of the upload for the preimage with the specified key hash and writes the journal to disk.
+func (j *UploadJournal) Remove(key common.Hash) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if _, ok := j.entries[key]; !ok {
+		return nil
+	}
+	delete(j.entries, key)
+	return j.write()
+}
+
+func (j *UploadJournal) write() error {
+	out, err := ioutil.NewAtomicWriterCompressed(j.path, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create upload journal (%v): %w", j.path, err)
+	}
+	if err := json.NewEncoder(out).Encode(j.entries); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to write upload journal (%v): %w", j.path, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write upload journal (%v): %w", j.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package preimages
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadJournal(t *testing.T) {
+	t.Run("MissingFile", func(t *testing.T) {
+		journal, err := NewUploadJournal(filepath.Join(t.TempDir(), "journal.json"))
+		require.NoError(t, err)
+		_, ok := journal.Get(common.Hash{0xaa})
+		require.False(t, ok)
+	})
+
+	t.Run("InvalidFile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "journal.json")
+		require.NoError(t, os.WriteFile(path, []byte("not json"), 0o644))
+		_, err := NewUploadJournal(path)
+		require.ErrorContains(t, err, "invalid upload journal")
+	})
+
+	t.Run("PersistEntries", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "journal.json")
+		journal, err := NewUploadJournal(path)
+		require.NoError(t, err)
+		entry1 := JournalEntry{UUID: big.NewInt(123), LeavesAdded: 5}
+		entry2 := JournalEntry{UUID: new(big.Int).Lsh(big.NewInt(1), 255), LeavesAdded: 0}
+		require.NoError(t, journal.Record(common.Hash{0xaa}, entry1))
+		require.NoError(t, journal.Record(common.Hash{0xbb}, entry2))
+
+		reloaded, err := NewUploadJournal(path)
+		require.NoError(t, err)
+		actual, ok := reloaded.Get(common.Hash{0xaa})
+		require.True(t, ok)
+		require.Equal(t, entry1, actual)
+		actual, ok = reloaded.Get(common.Hash{0xbb})
+		require.True(t, ok)
+		require.Equal(t, entry2, actual)
+	})
+
+	t.Run("PersistCompressedEntries", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "journal.json.gz")
+		journal, err := NewUploadJournal(path)
+		require.NoError(t, err)
+		entry := JournalEntry{UUID: big.NewInt(123), LeavesAdded: 5}
+		require.NoError(t, journal.Record(common.Hash{0xaa}, entry))
+
+		// The journal is written gzip compressed.
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, []byte{0x1f, 0x8b}, content[:2])
+
+		reloaded, err := NewUploadJournal(path)
+		require.NoError(t, err)
+		actual, ok := reloaded.Get(common.Hash{0xaa})
+		require.True(t, ok)
+		require.Equal(t, entry, actual)
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "journal.json")
+		journal, err := NewUploadJournal(path)
+		require.NoError(t, err)
+		require.NoError(t, journal.Record(common.Hash{0xaa}, JournalEntry{UUID: big.NewInt(123), LeavesAdded: 5}))
+		require.NoError(t, journal.Remove(common.Hash{0xaa}))
+		require.NoError(t, journal.Remove(common.Hash{0xcc}))
+
+		reloaded, err := NewUploadJournal(path)
+		require.NoError(t, err)
+		_, ok := reloaded.Get(common.Hash{0xaa})
+		require.False(t, ok)
+	})
+}
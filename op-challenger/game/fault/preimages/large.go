@@ -2,39 +2,656 @@ package preimages
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
 
+	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/contracts"
 	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/types"
+	"github.com/ethereum-optimism/optimism/op-challenger/game/keccak/matrix"
+	"github.com/ethereum-optimism/optimism/op-challenger/game/keccak/merkle"
+	"github.com/ethereum-optimism/optimism/op-service/clock"
+	"github.com/ethereum-optimism/optimism/op-service/retry"
+	"github.com/ethereum-optimism/optimism/op-service/sources/batching"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 )
 
 var _ PreimageUploader = (*LargePreimageUploader)(nil)
 
-var errNotSupported = errors.New("not supported")
+var (
+	// ErrChallengePeriodNotOver is returned when the large preimage proposal is finalized but
+	// cannot be squeezed until the challenge period has elapsed.
+	ErrChallengePeriodNotOver = errors.New("challenge period not over")
+
+	// ErrEmptyPreimage is returned when the preimage has no data to upload.
+	ErrEmptyPreimage = errors.New("cannot upload empty preimage, small preimages must be loaded directly into the oracle rather than as a large preimage")
+	// ErrPreimageTooLarge is returned when the preimage requires more leaves than the oracle's merkle tree can hold.
+	ErrPreimageTooLarge = errors.New("preimage exceeds max large preimage size")
+
+	// ErrPreimageSizeMismatch is returned when the size prefix of the oracle data doesn't match the length of the
+	// preimage that follows it. Proposing the preimage would claim the wrong size.
+	ErrPreimageSizeMismatch = errors.New("preimage size prefix does not match preimage length")
+
+	// ErrTreeRootMismatch is returned when the root of the proposal's merkle tree in the oracle doesn't match the
+	// root of the tree of leaves built locally, indicating the leaves were not added as intended.
+	ErrTreeRootMismatch = errors.New("proposal tree root mismatch")
+
+	// ErrProposalCountered is returned when the large preimage proposal for the preimage has been countered.
+	// The proposal uuid is derived from the preimage so retrying would only resume the same countered proposal,
+	// which can never be squeezed.
+	ErrProposalCountered = errors.New("large preimage proposal countered")
+
+	// ErrTxReverted is returned when a transaction updating the large preimage proposal was included but reverted.
+	ErrTxReverted = errors.New("preimage tx reverted")
+
+	errTooFewLeaves = errors.New("large preimage must have at least two leaves to squeeze")
+)
+
+// defaultMaxSendAttempts is the default number of attempts made to send each transaction.
+const defaultMaxSendAttempts = 3
+
+// defaultSqueezePollInterval is the default time between checks for whether a proposal can be squeezed.
+const defaultSqueezePollInterval = 30 * time.Second
+
+// maxPreimageSize is the largest preimage that can be proposed. The preimage is always followed by a final,
+// partial leaf, so it must be strictly less than the size of the maximum number of leaves.
+const maxPreimageSize = merkle.MaxLeafCount*matrix.LeafSize - 1
+
+// LargePreimageMetricer records the progress of large preimage uploads.
+type LargePreimageMetricer interface {
+	RecordLargePreimageUploadInit()
+	RecordLargePreimageLeavesUploaded(count int)
+	RecordLargePreimageUploadComplete()
+}
 
 // LargePreimageUploader handles uploading large preimages by
 // streaming the merkleized preimage to the PreimageOracle contract,
 // tightly packed across multiple transactions.
 type LargePreimageUploader struct {
-	log log.Logger
+	log     log.Logger
+	metrics LargePreimageMetricer
 
 	txMgr    txmgr.TxManager
+	l1Head   L1HeaderSource
 	contract PreimageOracleContract
+
+	// MaxLeavesPerTx is the maximum number of leaves added to the proposal in a single transaction.
+	// Defaults to the number of leaves that fit within the calldata size limit.
+	MaxLeavesPerTx int
+	// DryRun builds the transactions required to upload the preimage and logs them, including their calldata
+	// size, without sending them. Useful for estimating the cost of an upload.
+	DryRun bool
+	// MaxSendAttempts is the maximum number of attempts made to send each transaction when sending fails with a
+	// transient error. Transactions that reverted, either when included or when estimating gas, are never retried.
+	MaxSendAttempts int
+	// GasLimit is the gas limit set on each transaction sent. Defaults to 0, estimating the gas limit of each
+	// transaction online through the [txmgr].
+	GasLimit uint64
+	// VerifyTreeRoot compares the root of the proposal's merkle tree in the oracle against the root of the leaves
+	// built locally once all leaves are added and again before squeezing, aborting the upload if they differ.
+	VerifyTreeRoot bool
+	// SqueezePollInterval is the time WaitAndSqueeze waits between checks for whether the challenge period is over.
+	SqueezePollInterval time.Duration
+	// OnLeafCommitment is optionally called with the index and state commitment of each leaf of the preimage, in
+	// order, each time UploadPreimage splits a preimage into leaves. Useful to debug the commitments of a proposal.
+	OnLeafCommitment func(leafIdx uint64, commitment common.Hash)
+	// Journal optionally records the progress of uploads to disk. Uploads are always resumed from the proposal in
+	// the oracle, with any disagreement with the journal logged. Disabled if nil.
+	Journal *UploadJournal
+
+	// sendRetryStrategy provides the delay between attempts to send a transaction.
+	sendRetryStrategy retry.Strategy
+	// stateMatrixFactory creates the state matrix used to split the preimage into leaves.
+	stateMatrixFactory StateMatrixFactory
+	// clock is only used to wait between checks for whether the proposal can be squeezed and between send attempts.
+	// Whether the challenge period is over is decided by the time of the L1 head, never by the clock.
+	clock clock.Clock
+}
+
+func NewLargePreimageUploader(logger log.Logger, m LargePreimageMetricer, txMgr txmgr.TxManager, l1Head L1HeaderSource, contract PreimageOracleContract) *LargePreimageUploader {
+	return &LargePreimageUploader{
+		log:                 logger,
+		metrics:             m,
+		txMgr:               txMgr,
+		l1Head:              l1Head,
+		contract:            contract,
+		MaxLeavesPerTx:      contracts.DefaultMaxLeavesPerTx,
+		MaxSendAttempts:     defaultMaxSendAttempts,
+		SqueezePollInterval: defaultSqueezePollInterval,
+		sendRetryStrategy:   retry.Exponential(),
+		stateMatrixFactory:  newStateMatrix,
+		clock:               clock.SystemClock,
+	}
+}
+
+// UploadPreimage uploads the preimage as a large preimage proposal, resuming any existing proposal for it.
+// Returns the hashes of the transactions sent, in the order they were sent, even if the upload failed.
+// Uploads take multiple calls to complete as the proposal can only be squeezed after the challenge period.
+func (p *LargePreimageUploader) UploadPreimage(ctx context.Context, parent uint64, data *types.PreimageOracleData) ([]common.Hash, error) {
+	if p.DryRun {
+		return nil, p.dryRun(data)
+	}
+	claimedSize, err := p.claimedSize(data)
+	if err != nil {
+		return nil, err
+	}
+	// Check the limit of the deployed oracle as well so oversized preimages are rejected before initializing.
+	maxProposalSize, err := p.contract.MaxProposalSize(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load max proposal size: %w", err)
+	}
+	if uint64(claimedSize) > maxProposalSize {
+		return nil, fmt.Errorf("%w: got %v bytes but the oracle allows at most %v bytes", ErrPreimageTooLarge, claimedSize, maxProposalSize)
+	}
+	uuid := p.newUUID(data, claimedSize)
+	leaves, prestate := p.newLeaves(data)
+	if p.OnLeafCommitment != nil {
+		for _, leaf := range leaves {
+			p.OnLeafCommitment(leaf.Index.Uint64(), leaf.StateCommitment)
+		}
+	}
+	key := common.BytesToHash(data.OracleKey)
+
+	// Check for an existing proposal with the same uuid so an interrupted upload can be resumed.
+	metadata, err := p.contract.GetProposalMetadata(ctx, batching.BlockLatest, p.ident(uuid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load metadata for large preimage with uuid: %s: %w", uuid, err)
+	}
+	if metadata.Countered {
+		p.log.Error("Large preimage proposal was countered", "uuid", uuid)
+		p.removeJournalEntry(key, uuid)
+		return nil, fmt.Errorf("%w: uuid: %s", ErrProposalCountered, uuid)
+	}
+	// The journal may be stale or from a proposal that was reorged out, so the leaves added to the proposal in the
+	// oracle are always used to resume the upload. Adding leaves isn't idempotent so trusting the journal could
+	// add duplicate leaves or skip leaves.
+	if entry, ok := p.journalEntry(key, uuid); ok && entry.LeavesAdded != uint64(metadata.BlocksProcessed) {
+		p.log.Warn("Large preimage journal does not match proposal, resuming from proposal", "uuid", uuid,
+			"journalLeavesAdded", entry.LeavesAdded, "leavesAdded", metadata.BlocksProcessed)
+		p.recordProgress(key, uuid, uint64(metadata.BlocksProcessed))
+	}
+	var txHashes []common.Hash
+	// The proposal has not been initialized if the claimed size is 0.
+	if metadata.ClaimedSize == 0 {
+		txHash, err := p.initLargePreimage(ctx, uuid, data.OracleOffset, claimedSize)
+		txHashes = appendTxHash(txHashes, txHash)
+		if err != nil {
+			return txHashes, fmt.Errorf("failed to initialize large preimage with uuid: %s: %w", uuid, err)
+		}
+		p.recordProgress(key, uuid, 0)
+	}
+	if int(metadata.BlocksProcessed) > len(leaves) {
+		return txHashes, fmt.Errorf("large preimage with uuid: %s has %v leaves but expected at most %v", uuid, metadata.BlocksProcessed, len(leaves))
+	}
+	// The proposal is finalized once all leaves have been added.
+	if metadata.Timestamp == 0 {
+		added, leafTxHashes, err := p.addLargePreimageLeafs(ctx, key, uuid, data.OracleOffset, leaves[metadata.BlocksProcessed:])
+		txHashes = append(txHashes, leafTxHashes...)
+		if err != nil {
+			p.log.Warn("Failed to add all leaves to large preimage", "uuid", uuid, "leavesAdded", int(metadata.BlocksProcessed)+added, "leafCount", len(leaves))
+			return txHashes, fmt.Errorf("failed to add leaves to large preimage with uuid: %s: %w", uuid, err)
+		}
+		if err := p.verifyTreeRoot(ctx, uuid, leaves); err != nil {
+			return txHashes, err
+		}
+		// The challenge period only starts once the final leaf has been added.
+		return txHashes, ErrChallengePeriodNotOver
+	}
+
+	challengePeriod, err := p.contract.ChallengePeriod(ctx)
+	if err != nil {
+		return txHashes, fmt.Errorf("failed to load challenge period: %w", err)
+	}
+	// The oracle checks the challenge period against the timestamp of the block the squeeze is included in, so
+	// compare with the time of the L1 head rather than the local clock which may be skewed.
+	head, err := p.l1Head.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return txHashes, fmt.Errorf("failed to load L1 head: %w", err)
+	}
+	readyAt := metadata.Timestamp + challengePeriod
+	if head.Time <= readyAt {
+		p.log.Debug("Large preimage challenge period not over", "uuid", uuid, "readyAt", readyAt, "l1HeadTime", head.Time)
+		return txHashes, ErrChallengePeriodNotOver
+	}
+	// The proposal may already have been squeezed, possibly by another instance using the same account.
+	digest := crypto.Keccak256Hash(data.GetPreimageWithoutSize())
+	squeezed, err := p.contract.IsProposalSqueezed(ctx, batching.BlockLatest, p.ident(uuid), digest)
+	if err != nil {
+		return txHashes, fmt.Errorf("failed to check if large preimage with uuid: %s was squeezed: %w", uuid, err)
+	}
+	if squeezed {
+		p.log.Info("Large preimage already squeezed", "uuid", uuid)
+		p.removeJournalEntry(key, uuid)
+		return txHashes, nil
+	}
+	if err := p.verifyTreeRoot(ctx, uuid, leaves); err != nil {
+		return txHashes, err
+	}
+	txHash, err := p.squeezeLargePreimage(ctx, uuid, digest, leaves, prestate)
+	txHashes = appendTxHash(txHashes, txHash)
+	if err != nil {
+		return txHashes, fmt.Errorf("failed to squeeze large preimage with uuid: %s: %w", uuid, err)
+	}
+	p.metrics.RecordLargePreimageUploadComplete()
+	p.removeJournalEntry(key, uuid)
+	return txHashes, nil
+}
+
+// ident returns the identifier of the large preimage proposal with uuid created by this uploader.
+func (p *LargePreimageUploader) ident(uuid *big.Int) contracts.LargePreimageIdentifier {
+	return contracts.LargePreimageIdentifier{Claimant: p.txMgr.From(), UUID: uuid}
+}
+
+// removeJournalEntry removes the completed upload of the preimage with key from the journal, if enabled.
+func (p *LargePreimageUploader) removeJournalEntry(key common.Hash, uuid *big.Int) {
+	if p.Journal == nil {
+		return
+	}
+	if err := p.Journal.Remove(key); err != nil {
+		p.log.Warn("Failed to remove large preimage upload from journal", "uuid", uuid, "err", err)
+	}
+}
+
+// WaitAndSqueeze uploads the preimage and then blocks, polling every SqueezePollInterval, until the challenge period
+// is over and the proposal can be squeezed. The proposal is always one created by this uploader's txmgr so only the
+// preimage is required to identify it and rebuild the leaves required to squeeze.
+// Returns the hashes of all transactions sent, in the order they were sent.
+func (p *LargePreimageUploader) WaitAndSqueeze(ctx context.Context, data *types.PreimageOracleData) ([]common.Hash, error) {
+	var txHashes []common.Hash
+	for {
+		sent, err := p.UploadPreimage(ctx, 0, data)
+		txHashes = append(txHashes, sent...)
+		if !errors.Is(err, ErrChallengePeriodNotOver) {
+			return txHashes, err
+		}
+		select {
+		case <-p.clock.After(p.SqueezePollInterval):
+		case <-ctx.Done():
+			return txHashes, ctx.Err()
+		}
+	}
+}
+
+// verifyTreeRoot checks that the root of the proposal's merkle tree in the oracle matches the root of leaves,
+// if VerifyTreeRoot is enabled.
+func (p *LargePreimageUploader) verifyTreeRoot(ctx context.Context, uuid *big.Int, leaves []contracts.Leaf) error {
+	if !p.VerifyTreeRoot {
+		return nil
+	}
+	expected, err := contracts.MerkleRoot(leaves)
+	if err != nil {
+		return err
+	}
+	actual, err := p.contract.GetProposalTreeRoot(ctx, batching.BlockLatest, p.ident(uuid))
+	if err != nil {
+		return fmt.Errorf("failed to load tree root for large preimage with uuid: %s: %w", uuid, err)
+	}
+	if actual != expected {
+		return fmt.Errorf("%w: large preimage with uuid: %s has root %v but expected %v", ErrTreeRootMismatch, uuid, actual, expected)
+	}
+	return nil
+}
+
+// appendTxHash appends txHash to txHashes unless no transaction was published.
+func appendTxHash(txHashes []common.Hash, txHash common.Hash) []common.Hash {
+	if txHash == (common.Hash{}) {
+		return txHashes
+	}
+	return append(txHashes, txHash)
+}
+
+// journalEntry returns the journal entry for the upload of the preimage with the specified key hash.
+// Entries for a different uuid are ignored, as they are from an upload of a different part of the preimage.
+func (p *LargePreimageUploader) journalEntry(key common.Hash, uuid *big.Int) (JournalEntry, bool) {
+	if p.Journal == nil {
+		return JournalEntry{}, false
+	}
+	entry, ok := p.Journal.Get(key)
+	if !ok || entry.UUID == nil || entry.UUID.Cmp(uuid) != 0 {
+		return JournalEntry{}, false
+	}
+	return entry, true
+}
+
+// recordProgress records in the journal that the first leavesAdded leaves have been added to the proposal.
+// Failing to update the journal doesn't fail the upload as progress can still be recovered from the chain.
+func (p *LargePreimageUploader) recordProgress(key common.Hash, uuid *big.Int, leavesAdded uint64) {
+	if p.Journal == nil {
+		return
+	}
+	if err := p.Journal.Record(key, JournalEntry{UUID: uuid, LeavesAdded: leavesAdded}); err != nil {
+		p.log.Warn("Failed to record large preimage upload progress in journal", "uuid", uuid, "err", err)
+	}
+}
+
+// BuildUploadTxs creates the transactions that initialize a new large preimage proposal for the preimage
+// and add all of its leaves, finalizing the proposal.
+// The proposal can only be squeezed after the challenge period so the squeeze transaction is not included.
+func (p *LargePreimageUploader) BuildUploadTxs(data *types.PreimageOracleData) ([]txmgr.TxCandidate, error) {
+	claimedSize, err := p.claimedSize(data)
+	if err != nil {
+		return nil, err
+	}
+	uuid := p.newUUID(data, claimedSize)
+	leaves, _ := p.newLeaves(data)
+	initTx, err := p.contract.InitLargePreimage(uuid, data.OracleOffset, claimedSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pre-image oracle tx: %w", err)
+	}
+	leafTxs, err := p.contract.AddLeaves(uuid, data.OracleOffset, leaves, true, p.MaxLeavesPerTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pre-image oracle tx: %w", err)
+	}
+	return append([]txmgr.TxCandidate{initTx}, leafTxs...), nil
+}
+
+// dryRun logs the transactions required to upload the preimage without sending them.
+func (p *LargePreimageUploader) dryRun(data *types.PreimageOracleData) error {
+	candidates, err := p.BuildUploadTxs(data)
+	if err != nil {
+		return err
+	}
+	totalCalldata := 0
+	for i, candidate := range candidates {
+		totalCalldata += len(candidate.TxData)
+		p.log.Info("Dry run large preimage tx", "index", i, "to", candidate.To, "calldataSize", len(candidate.TxData))
+	}
+	p.log.Info("Dry run large preimage upload", "txs", len(candidates), "totalCalldataSize", totalCalldata)
+	return nil
+}
+
+// claimedSize validates that the preimage can be uploaded as a large preimage and returns its size.
+func (p *LargePreimageUploader) claimedSize(data *types.PreimageOracleData) (uint32, error) {
+	if data == nil {
+		return 0, ErrNilPreimageData
+	}
+	if err := data.CheckPreimageSize(); err != nil {
+		return 0, err
+	}
+	preimageSize := len(data.GetPreimageWithoutSize())
+	if preimageSize == 0 {
+		return 0, ErrEmptyPreimage
+	}
+	if preimageSize > maxPreimageSize {
+		return 0, fmt.Errorf("%w: got %v bytes but max is %v bytes", ErrPreimageTooLarge, preimageSize, maxPreimageSize)
+	}
+	if prefixSize := binary.BigEndian.Uint64(data.OracleData[:types.PreimageSizePrefixLength]); prefixSize != uint64(preimageSize) {
+		return 0, fmt.Errorf("%w: prefix claims %v bytes but preimage is %v bytes", ErrPreimageSizeMismatch, prefixSize, preimageSize)
+	}
+	if err := data.CheckOracleOffset(); err != nil {
+		return 0, err
+	}
+	return uint32(preimageSize), nil
+}
+
+// newUUID derives the proposal uuid from the preimage so that the same preimage always maps to the same
+// proposal. This allows an interrupted upload to be found and resumed.
+func (p *LargePreimageUploader) newUUID(data *types.PreimageOracleData, claimedSize uint32) *big.Int {
+	sizes := make([]byte, 8)
+	binary.BigEndian.PutUint32(sizes[0:4], data.OracleOffset)
+	binary.BigEndian.PutUint32(sizes[4:8], claimedSize)
+	return crypto.Keccak256Hash(data.OracleKey, sizes).Big()
+}
+
+// newLeaves runs the preimage through the keccak permutation, creating a leaf with the
+// state commitment of the intermediate state matrix after each block is absorbed.
+// The state matrix prior to absorbing the final leaf is also returned as it is required to squeeze the proposal.
+func (p *LargePreimageUploader) newLeaves(data *types.PreimageOracleData) ([]contracts.Leaf, matrix.StateSnapshot) {
+	preimage := data.GetPreimageWithoutSize()
+	// The final leaf is always a partial block, which is padded when absorbed.
+	// If the preimage is an exact multiple of the block size, the final leaf contains only padding.
+	leafCount := len(preimage)/matrix.LeafSize + 1
+	stateMatrix := p.stateMatrixFactory()
+	var prestate matrix.StateSnapshot
+	for i := 0; i < leafCount; i++ {
+		start := i * matrix.LeafSize
+		end := min(start+matrix.LeafSize, len(preimage))
+		final := i == leafCount-1
+		if final {
+			prestate = stateMatrix.StateSnapshot()
+		}
+		stateMatrix.AbsorbLeaf(preimage[start:end], final)
+	}
+	// The state matrix assigns each leaf its index in the order absorbed, keeping them contiguous.
+	return stateMatrix.Leaves(), prestate
+}
+
+func (p *LargePreimageUploader) initLargePreimage(ctx context.Context, uuid *big.Int, partOffset uint32, claimedSize uint32) (common.Hash, error) {
+	candidate, err := p.contract.InitLargePreimage(uuid, partOffset, claimedSize)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to create pre-image oracle tx: %w", err)
+	}
+	initialized := func(ctx context.Context) (bool, error) {
+		metadata, err := p.contract.GetProposalMetadata(ctx, batching.BlockLatest, p.ident(uuid))
+		return metadata.ClaimedSize != 0, err
+	}
+	txHash, err := p.sendTxAndWait(ctx, p.log.New("uuid", uuid), candidate, initialized)
+	if err != nil {
+		return txHash, fmt.Errorf("failed to populate pre-image oracle: %w", err)
+	}
+	p.metrics.RecordLargePreimageUploadInit()
+	return txHash, nil
+}
+
+// addLargePreimageLeafs adds the leaves to the large preimage proposal and finalizes it.
+// The final leaf in leaves is always the final leaf of the preimage.
+// Adding leaves is not idempotent, so after a failed send the number of leaves in the proposal is reloaded and
+// the transactions for the remaining leaves rebuilt before retrying, in case the failed transaction was included.
+// Returns the number of leaves from the start of leaves that were successfully added, which is accurate even if
+// adding the leaves failed part way, and the hashes of the published transactions in leaf order.
+func (p *LargePreimageUploader) addLargePreimageLeafs(ctx context.Context, key common.Hash, uuid *big.Int, partOffset uint32, leaves []contracts.Leaf) (int, []common.Hash, error) {
+	logger := p.log.New("uuid", uuid)
+	attempts := max(p.MaxSendAttempts, 1)
+	var txHashes []common.Hash
+	added := 0
+	failures := 0
+	for {
+		batches, candidates, err := p.buildLeafTxs(uuid, partOffset, leaves[added:])
+		if err != nil {
+			return added, txHashes, err
+		}
+		sent, published, err := p.sendLeaves(ctx, logger, key, uuid, batches, candidates)
+		txHashes = append(txHashes, published...)
+		added += sent
+		if err == nil {
+			return added, txHashes, nil
+		}
+		// Only consecutive failures without adding any leaves count towards the attempts for a transaction.
+		if sent > 0 {
+			failures = 0
+		}
+		failures++
+		if failures >= attempts || !isRetryableSendErr(ctx, err) {
+			return added, txHashes, fmt.Errorf("failed to populate pre-image oracle: %w", err)
+		}
+		if err := p.waitToRetry(ctx, logger, failures, err); err != nil {
+			return added, txHashes, fmt.Errorf("failed to populate pre-image oracle: %w", err)
+		}
+		metadata, err := p.contract.GetProposalMetadata(ctx, batching.BlockLatest, p.ident(uuid))
+		if err != nil {
+			return added, txHashes, fmt.Errorf("failed to load metadata for large preimage with uuid: %s: %w", uuid, err)
+		}
+		firstLeaf := leaves[0].Index.Uint64()
+		processed := uint64(metadata.BlocksProcessed)
+		if processed < firstLeaf || processed > firstLeaf+uint64(len(leaves)) {
+			return added, txHashes, fmt.Errorf("large preimage with uuid: %s has %v leaves but expected between %v and %v", uuid, processed, firstLeaf, firstLeaf+uint64(len(leaves)))
+		}
+		if int(processed-firstLeaf) != added {
+			logger.Warn("Failed large preimage tx was included", "leavesAdded", processed)
+			added = int(processed - firstLeaf)
+			p.recordProgress(key, uuid, processed)
+		}
+		// The proposal is finalized once the final leaf has been added.
+		if metadata.Timestamp != 0 {
+			return added, txHashes, nil
+		}
+	}
+}
+
+// buildLeafTxs creates the transactions adding leaves to the large preimage proposal, finalizing it with the last,
+// and returns them along with the batch of leaves added by each.
+func (p *LargePreimageUploader) buildLeafTxs(uuid *big.Int, partOffset uint32, leaves []contracts.Leaf) ([][]contracts.Leaf, []txmgr.TxCandidate, error) {
+	batches, err := contracts.SplitLeaves(partOffset, leaves, true, p.MaxLeavesPerTx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to split leaves: %w", err)
+	}
+	candidates, err := p.contract.AddLeaves(uuid, partOffset, leaves, true, p.MaxLeavesPerTx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create pre-image oracle tx: %w", err)
+	}
+	if len(candidates) != len(batches) {
+		return nil, nil, fmt.Errorf("expected %v add leaves txs but got %v", len(batches), len(candidates))
+	}
+	return batches, candidates, nil
+}
+
+// sendLeaves sends the transactions adding each batch of leaves to the large preimage proposal.
+// The contract appends leaves in the order transactions are included without checking their indices, so each
+// transaction is only sent once the previous transaction has been included. Sending them concurrently isn't safe:
+// the txmgr only assigns the nonce after estimating gas, so concurrent sends can be signed out of leaf order, and a
+// failed send resets the nonce used by every other pending send. Failed sends are not retried.
+// Returns the number of leaves that were added and the hashes of the published transactions.
+func (p *LargePreimageUploader) sendLeaves(ctx context.Context, logger log.Logger, key common.Hash, uuid *big.Int, batches [][]contracts.Leaf, candidates []txmgr.TxCandidate) (int, []common.Hash, error) {
+	var txHashes []common.Hash
+	added := 0
+	for i, batch := range batches {
+		if err := ctx.Err(); err != nil {
+			return added, txHashes, err
+		}
+		start := batch[0].Index.Uint64()
+		txLogger := logger.New("leafRange", fmt.Sprintf("%v-%v", start, start+uint64(len(batch))-1))
+		txHash, err := p.sendTxAndWaitOnce(ctx, txLogger, candidates[i])
+		txHashes = appendTxHash(txHashes, txHash)
+		if err != nil {
+			return added, txHashes, err
+		}
+		added += len(batch)
+		p.metrics.RecordLargePreimageLeavesUploaded(len(batch))
+		p.recordProgress(key, uuid, start+uint64(len(batch)))
+	}
+	return added, txHashes, nil
+}
+
+// squeezeLargePreimage finalizes the large preimage proposal, making the preimage part available in the oracle.
+// The final two leaves are proven against the merkle tree of all leaves in the proposal.
+// digest is the keccak256 hash of the preimage, used to check if the proposal was squeezed by a failed send.
+func (p *LargePreimageUploader) squeezeLargePreimage(ctx context.Context, uuid *big.Int, digest common.Hash, leaves []contracts.Leaf, prestate matrix.StateSnapshot) (common.Hash, error) {
+	if len(leaves) < 2 {
+		return common.Hash{}, errTooFewLeaves
+	}
+	tree := merkle.NewBinaryMerkleTree()
+	for _, leaf := range leaves {
+		if err := tree.AddLeaf(leaf.Hash()); err != nil {
+			return common.Hash{}, fmt.Errorf("failed to build merkle tree: %w", err)
+		}
+	}
+	preState := leaves[len(leaves)-2]
+	preStateProof, err := tree.ProofAtIndex(preState.Index.Uint64())
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to create prestate proof: %w", err)
+	}
+	postState := leaves[len(leaves)-1]
+	postStateProof, err := tree.ProofAtIndex(postState.Index.Uint64())
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to create poststate proof: %w", err)
+	}
+	candidate, err := p.contract.Squeeze(p.ident(uuid), prestate, preState, preStateProof, postState, postStateProof)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to create pre-image oracle tx: %w", err)
+	}
+	squeezed := func(ctx context.Context) (bool, error) {
+		return p.contract.IsProposalSqueezed(ctx, batching.BlockLatest, p.ident(uuid), digest)
+	}
+	txHash, err := p.sendTxAndWait(ctx, p.log.New("uuid", uuid), candidate, squeezed)
+	if err != nil {
+		return txHash, fmt.Errorf("failed to populate pre-image oracle: %w", err)
+	}
+	return txHash, nil
+}
+
+// sendTxAndWait sends a transaction through the [txmgr] and waits for a receipt.
+// Sending is retried with an exponential backoff if it fails with a transient error such as an RPC timeout.
+// A failed send may still have been included, so before each retry included is called to check if the transaction's
+// effect is already visible on chain, in which case the transaction is not sent again.
+// Returns ErrTxReverted if the transaction reverted, as later transactions depend on it succeeding.
+// The logger should include the context of the transaction, such as the proposal uuid.
+// Returns the hash of the published transaction, including if it reverted, or the zero hash if none was published.
+func (p *LargePreimageUploader) sendTxAndWait(ctx context.Context, logger log.Logger, candidate txmgr.TxCandidate, included func(ctx context.Context) (bool, error)) (common.Hash, error) {
+	attempts := max(p.MaxSendAttempts, 1)
+	var txHash common.Hash
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := p.waitToRetry(ctx, logger, attempt, err); err != nil {
+				return common.Hash{}, err
+			}
+			ok, err := included(ctx)
+			if err != nil {
+				return common.Hash{}, fmt.Errorf("failed to check if tx was included: %w", err)
+			}
+			if ok {
+				logger.Warn("Failed LargePreimageUploader tx was included")
+				return common.Hash{}, nil
+			}
+		}
+		txHash, err = p.sendTxAndWaitOnce(ctx, logger, candidate)
+		if err == nil || !isRetryableSendErr(ctx, err) {
+			return txHash, err
+		}
+	}
+	return common.Hash{}, fmt.Errorf("failed to send tx after %v attempts: %w", attempts, err)
+}
+
+// waitToRetry waits for the backoff delay before the specified attempt to send a transaction, logging err which
+// caused the previous attempt to fail. Returns the context error if ctx is done before the delay is over.
+func (p *LargePreimageUploader) waitToRetry(ctx context.Context, logger log.Logger, attempt int, err error) error {
+	delay := p.sendRetryStrategy.Duration(attempt - 1)
+	logger.Warn("Retrying LargePreimageUploader tx", "attempt", attempt+1, "delay", delay, "err", err)
+	select {
+	case <-p.clock.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isRetryableSendErr returns true if err may be resolved by sending the transaction again.
+// Transactions that reverted, either when included or when estimating gas, would revert again and errors after the
+// context is done or the txmgr is closed are final.
+func isRetryableSendErr(ctx context.Context, err error) bool {
+	return ctx.Err() == nil &&
+		!errors.Is(err, ErrTxReverted) &&
+		!errors.Is(err, txmgr.ErrClosed) &&
+		!isEstimationRevert(err)
 }
 
-func NewLargePreimageUploader(logger log.Logger, txMgr txmgr.TxManager, contract PreimageOracleContract) *LargePreimageUploader {
-	return &LargePreimageUploader{logger, txMgr, contract}
+// isEstimationRevert returns true if err is from the transaction reverting while the [txmgr] estimated its gas limit.
+// The RPC error is wrapped as a string, so it can only be identified by its message.
+func isEstimationRevert(err error) bool {
+	return strings.Contains(err.Error(), vm.ErrExecutionReverted.Error())
 }
 
-func (p *LargePreimageUploader) UploadPreimage(ctx context.Context, parent uint64, data *types.PreimageOracleData) error {
-	// todo(proofs#467): generate the full preimage
-	// todo(proofs#467): run the preimage through the keccak permutation, hashing
-	//                   the intermediate state matrix after each block is applied.
-	// todo(proofs#467): split up the preimage into chunks and submit the preimages
-	//                   and state commitments to the preimage oracle contract using
-	//                   `PreimageOracle.addLeavesLPP` (`_finalize` = false).
-	// todo(proofs#467): track the challenge period starting once the full preimage is posted.
-	// todo(proofs#467): once the challenge period is over, call `squeezeLPP` on the preimage oracle contract.
-	return errNotSupported
+// sendTxAndWaitOnce sends a transaction through the [txmgr] and waits for a receipt, without retrying.
+// This sets the tx GasLimit to the configured GasLimit, which performs gas estimation online through the [txmgr]
+// if it is 0.
+func (p *LargePreimageUploader) sendTxAndWaitOnce(ctx context.Context, logger log.Logger, candidate txmgr.TxCandidate) (common.Hash, error) {
+	candidate.GasLimit = p.GasLimit
+	receipt, err := p.txMgr.Send(ctx, candidate)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if receipt.Status == ethtypes.ReceiptStatusFailed {
+		logger.Error("LargePreimageUploader tx successfully published but reverted", "tx_hash", receipt.TxHash)
+		return receipt.TxHash, fmt.Errorf("%w: %s", ErrTxReverted, receipt.TxHash)
+	}
+	logger.Debug("LargePreimageUploader tx successfully published", "tx_hash", receipt.TxHash)
+	return receipt.TxHash, nil
 }
@@ -1,27 +1,1643 @@
 package preimages
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/contracts"
 	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/types"
+	"github.com/ethereum-optimism/optimism/op-challenger/game/keccak/matrix"
+	"github.com/ethereum-optimism/optimism/op-challenger/game/keccak/merkle"
+	"github.com/ethereum-optimism/optimism/op-service/clock"
+	"github.com/ethereum-optimism/optimism/op-service/retry"
+	"github.com/ethereum-optimism/optimism/op-service/sources/batching"
 	"github.com/ethereum-optimism/optimism/op-service/testlog"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/stretchr/testify/require"
 )
 
+var (
+	mockInitLPPError          = errors.New("mock init LPP error")
+	mockAddLeavesError        = errors.New("mock add leaves error")
+	mockProposalMetadataError = errors.New("mock proposal metadata error")
+	mockSqueezeError          = errors.New("mock squeeze error")
+	mockChallengePeriodError  = errors.New("mock challenge period error")
+	mockTreeRootError         = errors.New("mock tree root error")
+	mockMaxProposalSizeError  = errors.New("mock max proposal size error")
+	mockIsSqueezedError       = errors.New("mock is squeezed error")
+)
+
 func TestLargePreimageUploader_UploadPreimage(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
+	t.Run("NilPreimageData", func(t *testing.T) {
 		oracle, _, _ := newTestLargePreimageUploader(t)
-		err := oracle.UploadPreimage(context.Background(), 0, &types.PreimageOracleData{})
-		// todo(proofs#467): fix this to not error. See LargePreimageUploader.UploadPreimage.
-		require.ErrorIs(t, err, errNotSupported)
+		_, err := oracle.UploadPreimage(context.Background(), 0, nil)
+		require.ErrorIs(t, err, ErrNilPreimageData)
+	})
+
+	t.Run("EmptyPreimage", func(t *testing.T) {
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		_, err := oracle.UploadPreimage(context.Background(), 0, makePreimageData(0, 0))
+		require.ErrorIs(t, err, ErrEmptyPreimage)
+		require.Equal(t, 0, contract.initCalls)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("MissingPreimageSize", func(t *testing.T) {
+		oracle, txMgr, _ := newTestLargePreimageUploader(t)
+		data := types.NewPreimageOracleData(common.Hash{0xaa}.Bytes(), make([]byte, 7), 0)
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, types.ErrMissingPreimageSize)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("PreimageTooLarge", func(t *testing.T) {
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		_, err := oracle.UploadPreimage(context.Background(), 0, makePreimageData(maxPreimageSize+1, 0))
+		require.ErrorIs(t, err, ErrPreimageTooLarge)
+		require.Equal(t, 0, contract.initCalls)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("ExceedsOracleMaxProposalSize", func(t *testing.T) {
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		contract.maxProposalSize = 500
+		_, err := oracle.UploadPreimage(context.Background(), 0, makePreimageData(501, 0))
+		require.ErrorIs(t, err, ErrPreimageTooLarge)
+		require.Equal(t, 0, contract.initCalls)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("OracleMaxProposalSize", func(t *testing.T) {
+		oracle, _, contract := newTestLargePreimageUploader(t)
+		contract.maxProposalSize = 500
+		_, err := oracle.UploadPreimage(context.Background(), 0, makePreimageData(500, 0))
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+		require.Equal(t, 1, contract.initCalls)
+	})
+
+	t.Run("MaxProposalSizeFails", func(t *testing.T) {
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		contract.maxProposalSizeFails = true
+		_, err := oracle.UploadPreimage(context.Background(), 0, makePreimageData(500, 0))
+		require.ErrorIs(t, err, mockMaxProposalSizeError)
+		require.Equal(t, 0, contract.initCalls)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("PreimageSizeMismatch", func(t *testing.T) {
+		for _, prefixSize := range []uint64{0, 499, 501} {
+			oracle, txMgr, contract := newTestLargePreimageUploader(t)
+			data := makePreimageData(500, 0)
+			binary.BigEndian.PutUint64(data.OracleData[:8], prefixSize)
+			_, err := oracle.UploadPreimage(context.Background(), 0, data)
+			require.ErrorIs(t, err, ErrPreimageSizeMismatch)
+			require.Equal(t, 0, contract.initCalls)
+			require.Equal(t, 0, txMgr.sends)
+		}
+	})
+
+	t.Run("OracleOffsetOutOfBounds", func(t *testing.T) {
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		data := makePreimageData(500, 508)
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, types.ErrOracleOffsetOutOfBounds)
+		require.Equal(t, 0, contract.initCalls)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("MaxPreimageSize", func(t *testing.T) {
+		oracle, _, contract := newTestLargePreimageUploader(t)
+		data := makePreimageData(maxPreimageSize, 0)
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+		require.Len(t, contract.leaves, merkle.MaxLeafCount)
+	})
+
+	t.Run("ProposalMetadataFails", func(t *testing.T) {
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		contract.metadataFails = true
+		_, err := oracle.UploadPreimage(context.Background(), 0, makePreimageData(500, 0))
+		require.ErrorIs(t, err, mockProposalMetadataError)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("InitFails", func(t *testing.T) {
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		contract.initFails = true
+		_, err := oracle.UploadPreimage(context.Background(), 0, makePreimageData(500, 0))
+		require.ErrorIs(t, err, mockInitLPPError)
+		require.Equal(t, 1, contract.initCalls)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("AddLeavesFails", func(t *testing.T) {
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		contract.addFails = true
+		_, err := oracle.UploadPreimage(context.Background(), 0, makePreimageData(500, 0))
+		require.ErrorIs(t, err, mockAddLeavesError)
+		require.Equal(t, 1, contract.addCalls)
+		require.Equal(t, 1, txMgr.sends) // Only the init tx was sent
+	})
+
+	t.Run("InitReverted", func(t *testing.T) {
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		txMgr.statusFail = true
+		_, err := oracle.UploadPreimage(context.Background(), 0, makePreimageData(500, 0))
+		require.ErrorIs(t, err, ErrTxReverted)
+		require.Equal(t, 1, contract.initCalls)
+		require.Equal(t, 0, contract.addCalls)
+		require.Equal(t, 1, txMgr.sends)
+	})
+
+	t.Run("AddLeavesReverted", func(t *testing.T) {
+		data := makePreimageData(matrix.LeafSize*10, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		oracle.MaxLeavesPerTx = 4
+		contract.metadata = contracts.LargePreimageMetaData{ClaimedSize: uint32(len(data.GetPreimageWithoutSize()))}
+		txMgr.statusFail = true
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrTxReverted)
+		require.Equal(t, 1, contract.addCalls)
+		// No further leaves are sent after the first reverted tx.
+		require.Equal(t, 1, txMgr.sends)
+		require.Equal(t, 0, contract.squeezeCalls)
+	})
+
+	t.Run("AddLeavesSuccess", func(t *testing.T) {
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		data := makePreimageData(500, 0)
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+		require.Equal(t, 1, contract.initCalls)
+		require.Equal(t, 1, contract.addCalls)
+		require.Equal(t, 0, contract.squeezeCalls)
+		require.Equal(t, 2, txMgr.sends)
+		require.Equal(t, []bool{true}, contract.finalized)
+		requireLeaves(t, data, contract.leaves)
+	})
+
+	t.Run("ReturnsTxHashes", func(t *testing.T) {
+		oracle, txMgr, _ := newTestLargePreimageUploader(t)
+		oracle.MaxLeavesPerTx = 2
+		data := makePreimageData(matrix.LeafSize*4, 0)
+		txHashes, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+		// The init tx followed by the three txs adding leaves, in the order they were sent.
+		require.Len(t, txHashes, 4)
+		require.Equal(t, txMgr.txHashes, txHashes)
+	})
+
+	t.Run("DefaultGasLimitEstimated", func(t *testing.T) {
+		oracle, txMgr, _ := newTestLargePreimageUploader(t)
+		_, err := oracle.UploadPreimage(context.Background(), 0, makePreimageData(500, 0))
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+		require.Len(t, txMgr.candidates, 2)
+		for _, candidate := range txMgr.candidates {
+			require.Zero(t, candidate.GasLimit)
+		}
+	})
+
+	t.Run("GasLimitOverride", func(t *testing.T) {
+		oracle, txMgr, _ := newTestLargePreimageUploader(t)
+		oracle.GasLimit = 5_000_000
+		_, err := oracle.UploadPreimage(context.Background(), 0, makePreimageData(500, 0))
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+		require.Len(t, txMgr.candidates, 2)
+		for _, candidate := range txMgr.candidates {
+			require.Equal(t, uint64(5_000_000), candidate.GasLimit)
+		}
+	})
+
+	t.Run("ReturnsTxHashesOnFailure", func(t *testing.T) {
+		oracle, txMgr, _ := newTestLargePreimageUploader(t)
+		txMgr.statusFail = true
+		data := makePreimageData(500, 0)
+		txHashes, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrTxReverted)
+		// The reverted init tx was still published.
+		require.Equal(t, txMgr.txHashes, txHashes)
+		require.Len(t, txHashes, 1)
+	})
+
+	t.Run("ExistingProposalSkipsInit", func(t *testing.T) {
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		data := makePreimageData(500, 0)
+		contract.metadata = contracts.LargePreimageMetaData{ClaimedSize: uint32(len(data.GetPreimageWithoutSize()))}
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+		require.Equal(t, 0, contract.initCalls)
+		require.Equal(t, 1, contract.addCalls)
+		require.Equal(t, 1, txMgr.sends)
+		requireLeaves(t, data, contract.leaves)
+	})
+
+	t.Run("ChallengePeriodFails", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		contract.metadata = finalizedMetadata(data, 1234)
+		contract.challengePeriodFails = true
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, mockChallengePeriodError)
+		require.Equal(t, 0, contract.squeezeCalls)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("ChallengePeriodNotElapsed", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		contract.metadata = finalizedMetadata(data, uint64(time.Now().Unix()))
+		contract.challengePeriod = 1000
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+		require.Equal(t, 0, contract.initCalls)
+		require.Equal(t, 0, contract.addCalls)
+		require.Equal(t, 0, contract.squeezeCalls)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("ChallengePeriodElapsed", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		contract.metadata = finalizedMetadata(data, uint64(time.Now().Unix())-2000)
+		contract.challengePeriod = 1000
+		txHashes, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.NoError(t, err)
+		require.Equal(t, 0, contract.initCalls)
+		require.Equal(t, 0, contract.addCalls)
+		require.Equal(t, 1, txMgr.sends)
+		require.Equal(t, txMgr.txHashes, txHashes)
+		leaves, _ := oracle.newLeaves(data)
+		requireSqueeze(t, contract, leaves)
+	})
+
+	t.Run("ChallengePeriodUsesL1HeadTimeNotClock", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		l1Head := &stubL1HeaderSource{time: 5000}
+		oracle.l1Head = l1Head
+		// The local clock is well past the end of the challenge period but only the L1 head time is used.
+		oracle.clock = clock.NewDeterministicClock(time.Unix(10_000, 0))
+		contract.metadata = finalizedMetadata(data, 4000)
+		contract.challengePeriod = 1000
+
+		// Exactly at the end of the challenge period is not yet enough to squeeze.
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+		require.Equal(t, 0, contract.squeezeCalls)
+		require.Equal(t, 0, txMgr.sends)
+
+		l1Head.time = 5001
+		txHashes, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.NoError(t, err)
+		require.Equal(t, 1, contract.squeezeCalls)
+		require.Equal(t, txMgr.txHashes, txHashes)
+	})
+
+	t.Run("L1HeadFails", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		oracle.l1Head = &stubL1HeaderSource{err: errors.New("boom")}
+		contract.metadata = finalizedMetadata(data, 1234)
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorContains(t, err, "boom")
+		require.Equal(t, 0, contract.squeezeCalls)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("AlreadySqueezed", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		contract.metadata = finalizedMetadata(data, 1234)
+		contract.squeezed = true
+		txHashes, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.NoError(t, err)
+		require.Empty(t, txHashes)
+		require.Equal(t, 1, contract.squeezedCalls)
+		require.Equal(t, 0, contract.squeezeCalls)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("ProposalCountered", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		contract.metadata = finalizedMetadata(data, 1234)
+		contract.metadata.Countered = true
+		txHashes, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrProposalCountered)
+		require.Empty(t, txHashes)
+		require.Equal(t, 0, contract.squeezedCalls)
+		require.Equal(t, 0, contract.squeezeCalls)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("UnfinalizedProposalCountered", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		contract.metadata = contracts.LargePreimageMetaData{ClaimedSize: uint32(len(data.GetPreimageWithoutSize())), Countered: true}
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrProposalCountered)
+		require.Equal(t, 0, contract.addCalls)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("IsSqueezedFails", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		contract.metadata = finalizedMetadata(data, 1234)
+		contract.squeezedFails = true
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, mockIsSqueezedError)
+		require.Equal(t, 0, contract.squeezeCalls)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("SqueezeFails", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		contract.metadata = finalizedMetadata(data, 1234)
+		contract.squeezeFails = true
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, mockSqueezeError)
+		require.Equal(t, 1, contract.squeezeCalls)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("TreeRootNotVerifiedByDefault", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, _, contract := newTestLargePreimageUploader(t)
+		contract.metadata = finalizedMetadata(data, 1234)
+		contract.treeRoot = common.Hash{0xbb}
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.NoError(t, err)
+		require.Equal(t, 0, contract.treeRootCalls)
+		require.Equal(t, 1, contract.squeezeCalls)
+	})
+
+	t.Run("TreeRootVerified", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		oracle.VerifyTreeRoot = true
+		contract.metadata = finalizedMetadata(data, 1234)
+		contract.treeRoot = leavesRoot(t, oracle, data)
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.NoError(t, err)
+		require.Equal(t, 1, contract.treeRootCalls)
+		require.Equal(t, 1, contract.squeezeCalls)
+		require.Equal(t, 1, txMgr.sends)
+	})
+
+	t.Run("TreeRootMismatchAbortsSqueeze", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		oracle.VerifyTreeRoot = true
+		contract.metadata = finalizedMetadata(data, 1234)
+		contract.treeRoot = common.Hash{0xbb}
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrTreeRootMismatch)
+		require.Equal(t, 0, contract.squeezeCalls)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("TreeRootFails", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, _, contract := newTestLargePreimageUploader(t)
+		oracle.VerifyTreeRoot = true
+		contract.metadata = finalizedMetadata(data, 1234)
+		contract.treeRootFails = true
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, mockTreeRootError)
+		require.Equal(t, 0, contract.squeezeCalls)
+	})
+
+	t.Run("TreeRootVerifiedAfterAddingLeaves", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, _, contract := newTestLargePreimageUploader(t)
+		oracle.VerifyTreeRoot = true
+		contract.treeRoot = leavesRoot(t, oracle, data)
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+		require.Equal(t, 1, contract.treeRootCalls)
+	})
+
+	t.Run("TreeRootMismatchAfterAddingLeaves", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, _, contract := newTestLargePreimageUploader(t)
+		oracle.VerifyTreeRoot = true
+		contract.treeRoot = common.Hash{0xbb}
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrTreeRootMismatch)
+		require.Equal(t, 1, contract.addCalls)
+	})
+
+	t.Run("TooFewLeavesToSqueeze", func(t *testing.T) {
+		data := makePreimageData(100, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		contract.metadata = finalizedMetadata(data, 1234)
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, errTooFewLeaves)
+		require.Equal(t, 0, contract.squeezeCalls)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("MultipleTransactions", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			size           int
+			offset         uint32
+			maxLeavesPerTx int
+			txLeaves       []int
+		}{
+			{"SingleTx", matrix.LeafSize * 10, 0, contracts.DefaultMaxLeavesPerTx, []int{11}},
+			{"PartialFinalTx", matrix.LeafSize * 10, 0, 4, []int{4, 4, 3}},
+			{"ExactTxs", matrix.LeafSize*8 - 1, 0, 4, []int{4, 4}},
+			{"OneLeafPerTx", matrix.LeafSize * 2, 0, 1, []int{1, 1, 1}},
+			// The part starts in the last 32 bytes of the first tx so must be moved to the start of the next tx.
+			{"PartNearTxBoundary", matrix.LeafSize * 10, 8 + matrix.LeafSize*4 - 20, 4, []int{3, 4, 4}},
+			{"PartBeforeTxBoundary", matrix.LeafSize * 10, 8 + matrix.LeafSize*4 - 40, 4, []int{4, 4, 3}},
+		}
+		for _, test := range tests {
+			test := test
+			t.Run(test.name, func(t *testing.T) {
+				oracle, txMgr, contract := newTestLargePreimageUploader(t)
+				oracle.MaxLeavesPerTx = test.maxLeavesPerTx
+				data := makePreimageData(test.size, test.offset)
+				_, err := oracle.UploadPreimage(context.Background(), 0, data)
+				require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+				require.Equal(t, 1, contract.initCalls)
+				require.Equal(t, 1, contract.addCalls)
+				require.Equal(t, test.txLeaves, contract.txLeaves)
+				require.Equal(t, len(test.txLeaves)+1, txMgr.sends)
+				require.Equal(t, []bool{true}, contract.finalized)
+				requireLeaves(t, data, contract.leaves)
+			})
+		}
+	})
+
+	t.Run("LeafTxsSentInLeafOrder", func(t *testing.T) {
+		oracle, _, contract := newTestLargePreimageUploader(t)
+		txMgr := newBlockingTxMgr()
+		txMgr.release(5)
+		oracle.txMgr = txMgr
+		oracle.MaxLeavesPerTx = 2
+		data := makePreimageData(matrix.LeafSize*7, 0)
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+		require.Equal(t, []int{2, 2, 2, 2}, contract.txLeaves)
+		// Each tx is only sent once the previous tx is included, so nonces are assigned in leaf order.
+		require.Equal(t, 1, txMgr.maxInFlight)
+		require.Len(t, txMgr.candidates, 5)
+		for i, candidate := range txMgr.candidates[1:] {
+			require.Equal(t, uint64(i*2), firstLeafIndex(candidate), "tx %v", i)
+		}
+	})
+
+	t.Run("ContextCancelled", func(t *testing.T) {
+		data := makePreimageData(matrix.LeafSize*10, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		oracle.MaxLeavesPerTx = 1
+		contract.metadata = contracts.LargePreimageMetaData{ClaimedSize: uint32(len(data.GetPreimageWithoutSize()))}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := oracle.UploadPreimage(ctx, 0, data)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 0, txMgr.sends)
+	})
+
+	t.Run("ContextCancelledDuringUpload", func(t *testing.T) {
+		data := makePreimageData(matrix.LeafSize*10, 0)
+		oracle, _, contract := newTestLargePreimageUploader(t)
+		txMgr := newBlockingTxMgr()
+		oracle.txMgr = txMgr
+		oracle.MaxLeavesPerTx = 1
+		contract.metadata = contracts.LargePreimageMetaData{ClaimedSize: uint32(len(data.GetPreimageWithoutSize()))}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		result := make(chan error, 1)
+		go func() {
+			_, err := oracle.UploadPreimage(ctx, 0, data)
+			result <- err
+		}()
+		// Allow two of the eleven leaf txs through, then cancel while the third is in flight.
+		txMgr.release(2)
+		require.Eventually(t, func() bool { return txMgr.sent() == 3 && txMgr.inFlight() == 1 }, 10*time.Second, 10*time.Millisecond)
+		cancel()
+		require.ErrorIs(t, <-result, context.Canceled)
+		require.Equal(t, 3, txMgr.sent())
+	})
+
+	t.Run("ResumeUpload", func(t *testing.T) {
+		data := makePreimageData(matrix.LeafSize*10, 0)
+		oracle, _, _ := newTestLargePreimageUploader(t)
+		leaves, _ := oracle.newLeaves(data)
+		require.Len(t, leaves, 11)
+		half := len(leaves) / 2
+
+		// Simulate a restart after half the leaves were added.
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		contract.metadata = contracts.LargePreimageMetaData{
+			ClaimedSize:     uint32(len(data.GetPreimageWithoutSize())),
+			BlocksProcessed: uint32(half),
+			BytesProcessed:  uint32(half * matrix.LeafSize),
+		}
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+		require.Equal(t, 0, contract.initCalls)
+		require.Equal(t, 1, contract.addCalls)
+		require.Equal(t, 1, txMgr.sends)
+		require.Equal(t, leaves[half:], contract.leaves)
+	})
+
+	t.Run("ResumeSkipsAddedLeaves", func(t *testing.T) {
+		data := makePreimageData(matrix.LeafSize*4+10, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		leaves, _ := oracle.newLeaves(data)
+		require.Len(t, leaves, 5)
+		contract.metadata = contracts.LargePreimageMetaData{
+			ClaimedSize:     uint32(len(data.GetPreimageWithoutSize())),
+			BlocksProcessed: 2,
+			BytesProcessed:  2 * matrix.LeafSize,
+		}
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+		require.Equal(t, 0, contract.initCalls)
+		require.Equal(t, 1, txMgr.sends)
+		require.Equal(t, leaves[2:], contract.leaves)
+		require.Equal(t, big.NewInt(2), contract.leaves[0].Index)
 	})
 }
 
+func TestLargePreimageUploader_Journal(t *testing.T) {
+	t.Run("RecordsProgress", func(t *testing.T) {
+		data := makePreimageData(matrix.LeafSize*4, 0)
+		oracle, _, _ := newTestLargePreimageUploader(t)
+		journal := newTestJournal(t, filepath.Join(t.TempDir(), "journal.json"))
+		oracle.Journal = journal
+		oracle.MaxLeavesPerTx = 2
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+
+		entry, ok := journal.Get(common.BytesToHash(data.OracleKey))
+		require.True(t, ok)
+		require.Equal(t, oracle.newUUID(data, uint32(len(data.GetPreimageWithoutSize()))), entry.UUID)
+		require.Equal(t, uint64(5), entry.LeavesAdded)
+	})
+
+	t.Run("ResumeAfterRestart", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "journal.json")
+		data := makePreimageData(matrix.LeafSize*4, 0)
+		oracle, _, _ := newTestLargePreimageUploader(t)
+		txMgr := newBlockingTxMgr()
+		// Fail adding the second leaf, after the init tx and the first leaf tx succeed.
+		txMgr.failAt = 3
+		txMgr.release(3)
+		oracle.txMgr = txMgr
+		oracle.Journal = newTestJournal(t, path)
+		oracle.MaxLeavesPerTx = 1
+		oracle.MaxSendAttempts = 1
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, mockTxMgrSendError)
+		leaves, _ := oracle.newLeaves(data)
+
+		// Restart with a new uploader, with the proposal matching the journal.
+		oracle, txMgr2, contract := newTestLargePreimageUploader(t)
+		journal := newTestJournal(t, path)
+		oracle.Journal = journal
+		contract.metadata = contracts.LargePreimageMetaData{ClaimedSize: uint32(len(data.GetPreimageWithoutSize())), BlocksProcessed: 1}
+		_, err = oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+		require.Equal(t, 0, contract.initCalls)
+		require.Equal(t, 1, txMgr2.sends)
+		require.Equal(t, leaves[1:], contract.leaves)
+		entry, ok := journal.Get(common.BytesToHash(data.OracleKey))
+		require.True(t, ok)
+		require.Equal(t, uint64(len(leaves)), entry.LeavesAdded)
+	})
+
+	t.Run("ProposalDisagreesWithJournal", func(t *testing.T) {
+		tests := []struct {
+			name            string
+			journalAdded    uint64
+			claimedSize     bool
+			blocksProcessed uint32
+			expectInit      bool
+		}{
+			{"ProposalAhead", 1, true, 3, false},
+			{"ProposalBehind", 4, true, 2, false},
+			{"ProposalNotInitialized", 3, false, 0, true},
+		}
+		for _, test := range tests {
+			test := test
+			t.Run(test.name, func(t *testing.T) {
+				data := makePreimageData(matrix.LeafSize*4, 0)
+				oracle, _, contract := newTestLargePreimageUploader(t)
+				journal := newTestJournal(t, filepath.Join(t.TempDir(), "journal.json"))
+				oracle.Journal = journal
+				key := common.BytesToHash(data.OracleKey)
+				uuid := oracle.newUUID(data, uint32(len(data.GetPreimageWithoutSize())))
+				require.NoError(t, journal.Record(key, JournalEntry{UUID: uuid, LeavesAdded: test.journalAdded}))
+				if test.claimedSize {
+					contract.metadata.ClaimedSize = uint32(len(data.GetPreimageWithoutSize()))
+				}
+				contract.metadata.BlocksProcessed = test.blocksProcessed
+				_, err := oracle.UploadPreimage(context.Background(), 0, data)
+				require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+				if test.expectInit {
+					require.Equal(t, 1, contract.initCalls)
+				} else {
+					require.Equal(t, 0, contract.initCalls)
+				}
+				leaves, _ := oracle.newLeaves(data)
+				require.Equal(t, leaves[test.blocksProcessed:], contract.leaves)
+			})
+		}
+	})
+
+	t.Run("CounteredProposalRemovesEntry", func(t *testing.T) {
+		data := makePreimageData(matrix.LeafSize*4, 0)
+		oracle, _, contract := newTestLargePreimageUploader(t)
+		journal := newTestJournal(t, filepath.Join(t.TempDir(), "journal.json"))
+		oracle.Journal = journal
+		key := common.BytesToHash(data.OracleKey)
+		uuid := oracle.newUUID(data, uint32(len(data.GetPreimageWithoutSize())))
+		require.NoError(t, journal.Record(key, JournalEntry{UUID: uuid, LeavesAdded: 2}))
+		contract.metadata = contracts.LargePreimageMetaData{ClaimedSize: uint32(len(data.GetPreimageWithoutSize())), BlocksProcessed: 2, Countered: true}
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrProposalCountered)
+		require.Equal(t, 0, contract.addCalls)
+		_, ok := journal.Get(key)
+		require.False(t, ok)
+	})
+
+	t.Run("IgnoresDifferentUUID", func(t *testing.T) {
+		data := makePreimageData(matrix.LeafSize*4, 0)
+		oracle, _, contract := newTestLargePreimageUploader(t)
+		journal := newTestJournal(t, filepath.Join(t.TempDir(), "journal.json"))
+		require.NoError(t, journal.Record(common.BytesToHash(data.OracleKey), JournalEntry{UUID: big.NewInt(1), LeavesAdded: 3}))
+		oracle.Journal = journal
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+		require.Equal(t, 1, contract.initCalls)
+		requireLeaves(t, data, contract.leaves)
+	})
+
+	t.Run("RemovedWhenComplete", func(t *testing.T) {
+		data := makePreimageData(matrix.LeafSize*4, 0)
+		oracle, _, contract := newTestLargePreimageUploader(t)
+		journal := newTestJournal(t, filepath.Join(t.TempDir(), "journal.json"))
+		oracle.Journal = journal
+		key := common.BytesToHash(data.OracleKey)
+		uuid := oracle.newUUID(data, uint32(len(data.GetPreimageWithoutSize())))
+		require.NoError(t, journal.Record(key, JournalEntry{UUID: uuid, LeavesAdded: 5}))
+		contract.metadata = finalizedMetadata(data, uint64(time.Now().Unix())-100)
+		contract.challengePeriod = 10
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.NoError(t, err)
+		require.Equal(t, 1, contract.squeezeCalls)
+		_, ok := journal.Get(key)
+		require.False(t, ok)
+	})
+}
+
+func newTestJournal(t *testing.T, path string) *UploadJournal {
+	journal, err := NewUploadJournal(path)
+	require.NoError(t, err)
+	return journal
+}
+
+func TestLargePreimageUploader_AddLeavesProgress(t *testing.T) {
+	t.Run("AllAdded", func(t *testing.T) {
+		oracle, _, _ := newTestLargePreimageUploader(t)
+		oracle.MaxLeavesPerTx = 2
+		leaves, _ := oracle.newLeaves(makePreimageData(matrix.LeafSize*5, 0))
+		require.Len(t, leaves, 6)
+		added, txHashes, err := oracle.addLargePreimageLeafs(context.Background(), common.Hash{0xaa}, big.NewInt(1), 0, leaves)
+		require.NoError(t, err)
+		require.Equal(t, 6, added)
+		require.Len(t, txHashes, 3)
+	})
+
+	t.Run("FailsOnThirdBatch", func(t *testing.T) {
+		oracle, txMgr, _ := newTestLargePreimageUploader(t)
+		oracle.MaxLeavesPerTx = 2
+		oracle.MaxSendAttempts = 1
+		txMgr.failAt = 3
+		leaves, _ := oracle.newLeaves(makePreimageData(matrix.LeafSize*7, 0))
+		require.Len(t, leaves, 8)
+		added, txHashes, err := oracle.addLargePreimageLeafs(context.Background(), common.Hash{0xaa}, big.NewInt(1), 0, leaves)
+		require.ErrorIs(t, err, mockTxMgrSendError)
+		// Only the leaves in the two successful batches were added.
+		require.Equal(t, 4, added)
+		require.Len(t, txHashes, 2)
+		require.Equal(t, 3, txMgr.sends)
+	})
+
+	t.Run("FailsAfterPartMovedToNextBatch", func(t *testing.T) {
+		oracle, txMgr, _ := newTestLargePreimageUploader(t)
+		oracle.MaxLeavesPerTx = 2
+		oracle.MaxSendAttempts = 1
+		txMgr.failAt = 3
+		leaves, _ := oracle.newLeaves(makePreimageData(matrix.LeafSize*5, 0))
+		require.Len(t, leaves, 6)
+		// The part is in the last 32 bytes of the second leaf so the first batch only contains the first leaf.
+		added, txHashes, err := oracle.addLargePreimageLeafs(context.Background(), common.Hash{0xaa}, big.NewInt(1), 8+matrix.LeafSize*2-10, leaves)
+		require.ErrorIs(t, err, mockTxMgrSendError)
+		require.Equal(t, 3, added)
+		require.Len(t, txHashes, 2)
+		require.Equal(t, 3, txMgr.sends)
+	})
+}
+
+func TestLargePreimageUploader_StateMatrixFactory(t *testing.T) {
+	fake := &fakeStateMatrix{}
+	oracle, txMgr, contract := newTestLargePreimageUploader(t)
+	oracle.stateMatrixFactory = func() StateMatrix { return fake }
+	data := makePreimageData(matrix.LeafSize*2+10, 0)
+	_, err := oracle.UploadPreimage(context.Background(), 0, data)
+	require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+
+	preimage := data.GetPreimageWithoutSize()
+	require.Equal(t, [][]byte{preimage[:matrix.LeafSize], preimage[matrix.LeafSize : matrix.LeafSize*2], preimage[matrix.LeafSize*2:]}, fake.absorbed)
+	require.Equal(t, []bool{false, false, true}, fake.final)
+	// The leaves from the fake are uploaded as is.
+	require.Equal(t, fake.Leaves(), contract.leaves)
+	require.Equal(t, 2, txMgr.sends)
+}
+
+// fakeStateMatrix records the leaves absorbed without running the keccak permutation.
+type fakeStateMatrix struct {
+	absorbed [][]byte
+	final    []bool
+}
+
+func (f *fakeStateMatrix) AbsorbLeaf(data []byte, final bool) {
+	f.absorbed = append(f.absorbed, data)
+	f.final = append(f.final, final)
+}
+
+func (f *fakeStateMatrix) StateSnapshot() matrix.StateSnapshot {
+	return matrix.StateSnapshot{uint64(len(f.absorbed))}
+}
+
+func (f *fakeStateMatrix) Leaves() []contracts.Leaf {
+	leaves := make([]contracts.Leaf, len(f.absorbed))
+	for i, input := range f.absorbed {
+		leaves[i] = contracts.Leaf{Input: input, Index: big.NewInt(int64(i)), StateCommitment: common.Hash{byte(i + 1)}}
+	}
+	return leaves
+}
+
+func TestLargePreimageUploader_WaitAndSqueeze(t *testing.T) {
+	t.Run("SqueezesWhenReady", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		oracle.SqueezePollInterval = time.Millisecond
+		contract.challengePeriod = 1000
+		notReady := finalizedMetadata(data, uint64(time.Now().Unix()))
+		contract.metadataResponses = []contracts.LargePreimageMetaData{notReady, notReady}
+		contract.metadata = finalizedMetadata(data, uint64(time.Now().Unix())-2000)
+
+		txHashes, err := oracle.WaitAndSqueeze(context.Background(), data)
+		require.NoError(t, err)
+		require.Equal(t, 3, contract.metadataCalls)
+		require.Equal(t, 1, contract.squeezeCalls)
+		require.Equal(t, 1, txMgr.sends)
+		require.Equal(t, txMgr.txHashes, txHashes)
+	})
+
+	t.Run("UploadsLeavesBeforeWaiting", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		oracle.SqueezePollInterval = time.Millisecond
+		contract.challengePeriod = 1000
+		contract.metadataResponses = []contracts.LargePreimageMetaData{{}}
+		contract.metadata = finalizedMetadata(data, uint64(time.Now().Unix())-2000)
+
+		txHashes, err := oracle.WaitAndSqueeze(context.Background(), data)
+		require.NoError(t, err)
+		require.Equal(t, 1, contract.initCalls)
+		require.Equal(t, 1, contract.addCalls)
+		require.Equal(t, 1, contract.squeezeCalls)
+		require.Len(t, txHashes, 3)
+		require.Equal(t, txMgr.txHashes, txHashes)
+	})
+
+	t.Run("PollsUsingClock", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		cl := clock.NewDeterministicClock(time.Unix(1000, 0))
+		oracle.clock = cl
+		oracle.SqueezePollInterval = time.Minute
+		contract.challengePeriod = 1000
+		contract.metadataResponses = []contracts.LargePreimageMetaData{finalizedMetadata(data, uint64(time.Now().Unix()))}
+		contract.metadata = finalizedMetadata(data, uint64(time.Now().Unix())-2000)
+
+		result := make(chan error, 1)
+		go func() {
+			_, err := oracle.WaitAndSqueeze(context.Background(), data)
+			result <- err
+		}()
+		require.True(t, cl.WaitForNewPendingTaskWithTimeout(10*time.Second))
+		cl.AdvanceTime(oracle.SqueezePollInterval)
+		require.NoError(t, <-result)
+		require.Equal(t, 2, contract.metadataCalls)
+		require.Equal(t, 1, contract.squeezeCalls)
+		require.Equal(t, 1, txMgr.sends)
+	})
+
+	t.Run("ReturnsUploadErrors", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, _, contract := newTestLargePreimageUploader(t)
+		oracle.SqueezePollInterval = time.Millisecond
+		contract.metadataFails = true
+		_, err := oracle.WaitAndSqueeze(context.Background(), data)
+		require.ErrorIs(t, err, mockProposalMetadataError)
+		require.Equal(t, 1, contract.metadataCalls)
+	})
+
+	t.Run("ContextCancelled", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, _, contract := newTestLargePreimageUploader(t)
+		oracle.SqueezePollInterval = time.Hour
+		contract.challengePeriod = 1000
+		contract.metadata = finalizedMetadata(data, uint64(time.Now().Unix()))
+		ctx, cancel := context.WithCancel(context.Background())
+		result := make(chan error, 1)
+		go func() {
+			_, err := oracle.WaitAndSqueeze(ctx, data)
+			result <- err
+		}()
+		cancel()
+		require.ErrorIs(t, <-result, context.Canceled)
+		require.Equal(t, 0, contract.squeezeCalls)
+	})
+}
+
+func TestLargePreimageUploader_OnLeafCommitment(t *testing.T) {
+	oracle, _, _ := newTestLargePreimageUploader(t)
+	data := makePreimageData(matrix.LeafSize*3+10, 0)
+	var indices []uint64
+	var commitments []common.Hash
+	oracle.OnLeafCommitment = func(leafIdx uint64, commitment common.Hash) {
+		indices = append(indices, leafIdx)
+		commitments = append(commitments, commitment)
+	}
+	_, err := oracle.UploadPreimage(context.Background(), 0, data)
+	require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+
+	leaves, _ := oracle.newLeaves(data)
+	require.Len(t, leaves, 4)
+	require.Equal(t, []uint64{0, 1, 2, 3}, indices)
+	for i, leaf := range leaves {
+		require.Equal(t, leaf.StateCommitment, commitments[i])
+	}
+}
+
+func TestLargePreimageUploader_Logging(t *testing.T) {
+	data := makePreimageData(matrix.LeafSize*4, 0)
+	oracle, _, _ := newTestLargePreimageUploader(t)
+	logger := testlog.Logger(t, log.LvlDebug)
+	logs := testlog.Capture(logger)
+	oracle.log = logger
+	oracle.MaxLeavesPerTx = 2
+	_, err := oracle.UploadPreimage(context.Background(), 0, data)
+	require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+
+	uuid := oracle.newUUID(data, uint32(len(data.GetPreimageWithoutSize())))
+	var published []*testlog.HelperRecord
+	for _, record := range logs.Logs {
+		if record.Msg == "LargePreimageUploader tx successfully published" {
+			published = append(published, &testlog.HelperRecord{Record: record})
+		}
+	}
+	// The init tx and the three txs adding the five leaves.
+	require.Len(t, published, 4)
+	for _, record := range published {
+		require.Equal(t, uuid, record.GetContextValue("uuid"))
+	}
+	require.Nil(t, published[0].GetContextValue("leafRange"))
+	require.Equal(t, "0-1", published[1].GetContextValue("leafRange"))
+	require.Equal(t, "2-3", published[2].GetContextValue("leafRange"))
+	require.Equal(t, "4-4", published[3].GetContextValue("leafRange"))
+}
+
+func TestLargePreimageUploader_Metrics(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlError)
+	m := &mockLargePreimageMetrics{}
+	contract := &mockPreimageOracleContract{maxProposalSize: maxPreimageSize}
+	oracle := NewLargePreimageUploader(logger, m, &mockTxMgr{}, &stubL1HeaderSource{}, contract)
+	oracle.MaxLeavesPerTx = 2
+	data := makePreimageData(matrix.LeafSize*2+10, 0)
+
+	_, err := oracle.UploadPreimage(context.Background(), 0, data)
+	require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+	require.Equal(t, 1, m.inits)
+	require.Equal(t, []int{2, 1}, m.leavesUploaded)
+	require.Equal(t, 0, m.completes)
+
+	contract.metadata = finalizedMetadata(data, 1234)
+	_, err = oracle.UploadPreimage(context.Background(), 0, data)
+	require.NoError(t, err)
+	require.Equal(t, 1, m.inits)
+	require.Equal(t, []int{2, 1}, m.leavesUploaded)
+	require.Equal(t, 1, m.completes)
+}
+
+func TestLargePreimageUploader_NewUUID(t *testing.T) {
+	oracle, _, _ := newTestLargePreimageUploader(t)
+	data := makePreimageData(500, 0)
+	size := uint32(len(data.GetPreimageWithoutSize()))
+	uuid := oracle.newUUID(data, size)
+	require.Equal(t, uuid, oracle.newUUID(makePreimageData(500, 0), size), "same preimage should have same uuid")
+	require.NotEqual(t, uuid, oracle.newUUID(makePreimageData(500, 8), size), "different offset should have different uuid")
+	require.NotEqual(t, uuid, oracle.newUUID(makePreimageData(501, 0), size+1), "different size should have different uuid")
+}
+
+func TestLargePreimageUploader_NewLeaves(t *testing.T) {
+	tests := []struct {
+		name          string
+		size          int
+		expectedCount int
+	}{
+		{"SingleBlock", 100, 1},
+		{"ExactBlock", matrix.LeafSize, 2},
+		{"PartialBlock", matrix.LeafSize*2 + 1, 3},
+		{"MultipleBlocks", matrix.LeafSize * 5, 6},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			oracle, _, _ := newTestLargePreimageUploader(t)
+			data := makePreimageData(test.size, 0)
+			leaves, prestate := oracle.newLeaves(data)
+			require.Len(t, leaves, test.expectedCount)
+			requireLeaves(t, data, leaves)
+			if len(leaves) > 1 {
+				require.Equal(t, leaves[len(leaves)-2].StateCommitment, commitment(prestate))
+			} else {
+				require.Equal(t, matrix.NewStateMatrix().StateSnapshot(), prestate)
+			}
+		})
+	}
+}
+
+// FuzzNewLeaves checks the invariants of splitting a preimage into leaves for arbitrary preimages.
+func FuzzNewLeaves(f *testing.F) {
+	for _, size := range []int{0, 1, matrix.LeafSize - 1, matrix.LeafSize, matrix.LeafSize + 1, matrix.LeafSize * 3} {
+		f.Add(make([]byte, size))
+	}
+	f.Fuzz(func(t *testing.T, preimage []byte) {
+		oracleData := binary.BigEndian.AppendUint64(nil, uint64(len(preimage)))
+		oracleData = append(oracleData, preimage...)
+		data := types.NewPreimageOracleData(common.Hash{byte(2)}.Bytes(), oracleData, 0)
+		oracle := NewLargePreimageUploader(testlog.Logger(t, log.LvlCrit), &mockLargePreimageMetrics{}, &mockTxMgr{}, &stubL1HeaderSource{}, &mockPreimageOracleContract{})
+		leaves, _ := oracle.newLeaves(data)
+
+		// The preimage is split into full blocks followed by a final partial block.
+		fullBlocks := len(preimage) / matrix.LeafSize
+		require.Len(t, leaves, fullBlocks+1)
+		require.Equal(t, len(preimage), fullBlocks*matrix.LeafSize+data.LastLeafBytes())
+
+		var reassembled []byte
+		stateMatrix := matrix.NewStateMatrix()
+		paddedMatrix := matrix.NewStateMatrix()
+		for i, leaf := range leaves {
+			require.Equal(t, big.NewInt(int64(i)), leaf.Index)
+			final := i == len(leaves)-1
+			if final {
+				require.Len(t, leaf.Input, data.LastLeafBytes())
+			} else {
+				require.Len(t, leaf.Input, matrix.LeafSize)
+			}
+			stateMatrix.AbsorbLeaf(leaf.Input, final)
+			require.Equal(t, stateMatrix.StateCommitment(), leaf.StateCommitment)
+			// Every leaf is a full block once padded, and absorbing it gives the same state as the raw input.
+			padded := leaf.PaddedInput()
+			paddedMatrix.AbsorbLeaf(padded[:], false)
+			require.Equal(t, paddedMatrix.StateCommitment(), leaf.StateCommitment)
+			reassembled = append(reassembled, leaf.Input...)
+		}
+		require.True(t, bytes.Equal(preimage, reassembled), "reassembled leaves should match the preimage")
+		require.Equal(t, crypto.Keccak256Hash(preimage), stateMatrix.Hash())
+	})
+}
+
+// requireLeaves asserts that leaves contain the full preimage and the matching state commitments for data.
+func requireLeaves(t *testing.T, data *types.PreimageOracleData, leaves []contracts.Leaf) {
+	preimage := data.GetPreimageWithoutSize()
+	stateMatrix := matrix.NewStateMatrix()
+	var input []byte
+	for i, leaf := range leaves {
+		require.Equal(t, big.NewInt(int64(i)), leaf.Index)
+		final := i == len(leaves)-1
+		if final {
+			require.Len(t, leaf.Input, data.LastLeafBytes())
+		} else {
+			require.Len(t, leaf.Input, matrix.LeafSize)
+		}
+		stateMatrix.AbsorbLeaf(leaf.Input, final)
+		require.Equal(t, stateMatrix.StateCommitment(), leaf.StateCommitment)
+		input = append(input, leaf.Input...)
+	}
+	require.Equal(t, preimage, input)
+}
+
+// requireSqueeze asserts that the proposal was squeezed with the final two leaves and valid proofs.
+func requireSqueeze(t *testing.T, contract *mockPreimageOracleContract, leaves []contracts.Leaf) {
+	require.Equal(t, 1, contract.squeezeCalls)
+	tree := merkle.NewBinaryMerkleTree()
+	for _, leaf := range leaves {
+		require.NoError(t, tree.AddLeaf(leaf.Hash()))
+	}
+	root := tree.RootHash()
+	require.Equal(t, leaves[len(leaves)-2], contract.preState)
+	require.Equal(t, leaves[len(leaves)-1], contract.postState)
+	require.Equal(t, contract.preState.StateCommitment, commitment(contract.stateMatrix))
+	require.True(t, merkle.Verify(root, contract.preState.Index.Uint64(), contract.preState.Hash(), contract.preStateProof))
+	require.True(t, merkle.Verify(root, contract.postState.Index.Uint64(), contract.postState.Hash(), contract.postStateProof))
+}
+
+func commitment(snapshot matrix.StateSnapshot) common.Hash {
+	packed := make([]byte, 0, len(snapshot)*32)
+	for _, lane := range snapshot {
+		packed = append(packed, common.BigToHash(new(big.Int).SetUint64(lane)).Bytes()...)
+	}
+	return crypto.Keccak256Hash(packed)
+}
+
+// finalizedMetadata returns the metadata of a proposal for data with all leaves added at timestamp.
+// leavesRoot returns the root of the merkle tree of the leaves of the preimage.
+func leavesRoot(t *testing.T, oracle *LargePreimageUploader, data *types.PreimageOracleData) common.Hash {
+	leaves, _ := oracle.newLeaves(data)
+	root, err := contracts.MerkleRoot(leaves)
+	require.NoError(t, err)
+	return root
+}
+
+func finalizedMetadata(data *types.PreimageOracleData, timestamp uint64) contracts.LargePreimageMetaData {
+	size := uint32(len(data.GetPreimageWithoutSize()))
+	return contracts.LargePreimageMetaData{
+		Timestamp:       timestamp,
+		ClaimedSize:     size,
+		BlocksProcessed: size/matrix.LeafSize + 1,
+		BytesProcessed:  size,
+	}
+}
+
+func makePreimageData(size int, offset uint32) *types.PreimageOracleData {
+	data := make([]byte, 8+size)
+	binary.BigEndian.PutUint64(data[0:8], uint64(size))
+	for i := 8; i < len(data); i++ {
+		data[i] = byte(i)
+	}
+	key := common.Hash{byte(2), 0xaa, 0xbb}
+	return types.NewPreimageOracleData(key.Bytes(), data, offset)
+}
+
 func newTestLargePreimageUploader(t *testing.T) (*LargePreimageUploader, *mockTxMgr, *mockPreimageOracleContract) {
 	logger := testlog.Logger(t, log.LvlError)
 	txMgr := &mockTxMgr{}
-	contract := &mockPreimageOracleContract{}
-	return NewLargePreimageUploader(logger, txMgr, contract), txMgr, contract
+	contract := &mockPreimageOracleContract{maxProposalSize: maxPreimageSize}
+	return NewLargePreimageUploader(logger, &mockLargePreimageMetrics{}, txMgr, &stubL1HeaderSource{}, contract), txMgr, contract
+}
+
+// stubL1HeaderSource returns an L1 head with the specified time, or the current time if not set.
+type stubL1HeaderSource struct {
+	time uint64
+	err  error
+}
+
+func (s *stubL1HeaderSource) HeaderByNumber(_ context.Context, _ *big.Int) (*ethtypes.Header, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	headTime := s.time
+	if headTime == 0 {
+		headTime = uint64(time.Now().Unix())
+	}
+	return &ethtypes.Header{Time: headTime}, nil
+}
+
+type mockLargePreimageMetrics struct {
+	lock           sync.Mutex
+	inits          int
+	leavesUploaded []int
+	completes      int
+}
+
+func (m *mockLargePreimageMetrics) RecordLargePreimageUploadInit() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.inits++
+}
+
+func (m *mockLargePreimageMetrics) RecordLargePreimageLeavesUploaded(count int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.leavesUploaded = append(m.leavesUploaded, count)
+}
+
+func (m *mockLargePreimageMetrics) RecordLargePreimageUploadComplete() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.completes++
+}
+
+func TestLargePreimageUploader_DryRun(t *testing.T) {
+	tests := []struct {
+		name           string
+		size           int
+		maxLeavesPerTx int
+		expectedTxs    int
+	}{
+		{name: "SingleLeafTx", size: matrix.LeafSize * 2, maxLeavesPerTx: contracts.DefaultMaxLeavesPerTx, expectedTxs: 2},
+		{name: "MultipleLeafTxs", size: matrix.LeafSize * 9, maxLeavesPerTx: 3, expectedTxs: 1 + 4},
+		{name: "ExactLeafTxs", size: matrix.LeafSize*9 - 1, maxLeavesPerTx: 3, expectedTxs: 1 + 3},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			oracle, txMgr, _ := newTestLargePreimageUploader(t)
+			oracle.DryRun = true
+			oracle.MaxLeavesPerTx = test.maxLeavesPerTx
+			data := makePreimageData(test.size, 0)
+
+			_, err := oracle.UploadPreimage(context.Background(), 0, data)
+			require.NoError(t, err)
+			require.Equal(t, 0, txMgr.sends)
+
+			candidates, err := oracle.BuildUploadTxs(data)
+			require.NoError(t, err)
+			require.Len(t, candidates, test.expectedTxs)
+			require.Equal(t, 0, txMgr.sends)
+			leafCount := test.size/matrix.LeafSize + 1
+			require.Len(t, candidates, 1+(leafCount+test.maxLeavesPerTx-1)/test.maxLeavesPerTx)
+		})
+	}
+
+	t.Run("InvalidPreimage", func(t *testing.T) {
+		oracle, txMgr, _ := newTestLargePreimageUploader(t)
+		oracle.DryRun = true
+		_, err := oracle.UploadPreimage(context.Background(), 0, makePreimageData(0, 0))
+		require.ErrorIs(t, err, ErrEmptyPreimage)
+		require.Equal(t, 0, txMgr.sends)
+	})
+}
+
+func TestLargePreimageUploader_SendRetries(t *testing.T) {
+	t.Run("TransientErrorsRetried", func(t *testing.T) {
+		oracle, _, _ := newTestLargePreimageUploader(t)
+		txMgr := &flakyTxMgr{failures: 2}
+		oracle.txMgr = txMgr
+		oracle.sendRetryStrategy = retry.Fixed(0)
+		_, err := oracle.sendTxAndWait(context.Background(), oracle.log, txmgr.TxCandidate{}, notIncluded)
+		require.NoError(t, err)
+		require.Equal(t, 3, txMgr.sends)
+	})
+
+	t.Run("AttemptsExhausted", func(t *testing.T) {
+		oracle, _, _ := newTestLargePreimageUploader(t)
+		txMgr := &flakyTxMgr{failures: defaultMaxSendAttempts}
+		oracle.txMgr = txMgr
+		oracle.sendRetryStrategy = retry.Fixed(0)
+		_, err := oracle.sendTxAndWait(context.Background(), oracle.log, txmgr.TxCandidate{}, notIncluded)
+		require.ErrorIs(t, err, mockTxMgrSendError)
+		require.Equal(t, defaultMaxSendAttempts, txMgr.sends)
+	})
+
+	t.Run("RevertNotRetried", func(t *testing.T) {
+		oracle, txMgr, _ := newTestLargePreimageUploader(t)
+		txMgr.statusFail = true
+		oracle.sendRetryStrategy = retry.Fixed(0)
+		_, err := oracle.sendTxAndWait(context.Background(), oracle.log, txmgr.TxCandidate{}, notIncluded)
+		require.ErrorIs(t, err, ErrTxReverted)
+		require.Equal(t, 1, txMgr.sends)
+	})
+
+	t.Run("EstimationRevertNotRetried", func(t *testing.T) {
+		oracle, _, _ := newTestLargePreimageUploader(t)
+		txMgr := &flakyTxMgr{failures: 1, err: fmt.Errorf("failed to create the tx: failed to estimate gas: %w", errors.New("execution reverted: ActiveProposal"))}
+		oracle.txMgr = txMgr
+		oracle.sendRetryStrategy = retry.Fixed(0)
+		_, err := oracle.sendTxAndWait(context.Background(), oracle.log, txmgr.TxCandidate{}, notIncluded)
+		require.ErrorContains(t, err, "execution reverted")
+		require.Equal(t, 1, txMgr.sends)
+	})
+
+	t.Run("IncludedTxNotResent", func(t *testing.T) {
+		oracle, _, _ := newTestLargePreimageUploader(t)
+		txMgr := &flakyTxMgr{failures: 1}
+		oracle.txMgr = txMgr
+		oracle.sendRetryStrategy = retry.Fixed(0)
+		txHash, err := oracle.sendTxAndWait(context.Background(), oracle.log, txmgr.TxCandidate{}, func(ctx context.Context) (bool, error) {
+			return true, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, common.Hash{}, txHash)
+		require.Equal(t, 1, txMgr.sends)
+	})
+
+	t.Run("IncludedCheckFails", func(t *testing.T) {
+		oracle, _, _ := newTestLargePreimageUploader(t)
+		txMgr := &flakyTxMgr{failures: 1}
+		oracle.txMgr = txMgr
+		oracle.sendRetryStrategy = retry.Fixed(0)
+		_, err := oracle.sendTxAndWait(context.Background(), oracle.log, txmgr.TxCandidate{}, func(ctx context.Context) (bool, error) {
+			return false, mockProposalMetadataError
+		})
+		require.ErrorIs(t, err, mockProposalMetadataError)
+		require.Equal(t, 1, txMgr.sends)
+	})
+
+	t.Run("BackoffUsesClock", func(t *testing.T) {
+		oracle, _, _ := newTestLargePreimageUploader(t)
+		txMgr := &flakyTxMgr{failures: 1}
+		oracle.txMgr = txMgr
+		cl := clock.NewDeterministicClock(time.Unix(1000, 0))
+		oracle.clock = cl
+		oracle.sendRetryStrategy = retry.Fixed(time.Minute)
+		result := make(chan error, 1)
+		go func() {
+			_, err := oracle.sendTxAndWait(context.Background(), oracle.log, txmgr.TxCandidate{}, notIncluded)
+			result <- err
+		}()
+		require.True(t, cl.WaitForNewPendingTaskWithTimeout(10*time.Second))
+		cl.AdvanceTime(time.Minute)
+		require.NoError(t, <-result)
+		require.Equal(t, 2, txMgr.sends)
+	})
+
+	t.Run("ContextCancelledDuringBackoff", func(t *testing.T) {
+		oracle, _, _ := newTestLargePreimageUploader(t)
+		txMgr := &flakyTxMgr{failures: 1}
+		oracle.txMgr = txMgr
+		oracle.sendRetryStrategy = retry.Fixed(time.Hour)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := oracle.sendTxAndWait(ctx, oracle.log, txmgr.TxCandidate{}, notIncluded)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Equal(t, 1, txMgr.sends)
+	})
+
+	t.Run("InitNotResentWhenIncluded", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, _, contract := newTestLargePreimageUploader(t)
+		txMgr := &flakyTxMgr{failures: 1}
+		oracle.txMgr = txMgr
+		oracle.sendRetryStrategy = retry.Fixed(0)
+		claimedSize := uint32(len(data.GetPreimageWithoutSize()))
+		// The proposal isn't initialized when first loaded but the failed init tx was included.
+		contract.metadataResponses = []contracts.LargePreimageMetaData{{}}
+		contract.metadata = contracts.LargePreimageMetaData{ClaimedSize: claimedSize}
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.ErrorIs(t, err, ErrChallengePeriodNotOver)
+		require.Equal(t, 1, contract.initCalls)
+		// One failed init send and one send adding the leaves.
+		require.Equal(t, 2, txMgr.sends)
+	})
+
+	t.Run("SqueezeNotResentWhenIncluded", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		oracle, _, contract := newTestLargePreimageUploader(t)
+		txMgr := &flakyTxMgr{failures: 1}
+		oracle.txMgr = txMgr
+		oracle.sendRetryStrategy = retry.Fixed(0)
+		contract.metadata = finalizedMetadata(data, 1234)
+		// Squeezed is checked before squeezing and again before retrying, after the failed squeeze was included.
+		squeezedAfter := 1
+		contract.squeezedFn = func() bool {
+			squeezedAfter--
+			return squeezedAfter < 0
+		}
+		_, err := oracle.UploadPreimage(context.Background(), 0, data)
+		require.NoError(t, err)
+		require.Equal(t, 1, contract.squeezeCalls)
+		require.Equal(t, 1, txMgr.sends)
+	})
+}
+
+func TestLargePreimageUploader_AddLeavesRetries(t *testing.T) {
+	setup := func(t *testing.T) (*LargePreimageUploader, *mockTxMgr, *mockPreimageOracleContract, []contracts.Leaf) {
+		oracle, txMgr, contract := newTestLargePreimageUploader(t)
+		oracle.MaxLeavesPerTx = 2
+		oracle.sendRetryStrategy = retry.Fixed(0)
+		leaves, _ := oracle.newLeaves(makePreimageData(matrix.LeafSize*5, 0))
+		require.Len(t, leaves, 6)
+		return oracle, txMgr, contract, leaves
+	}
+
+	t.Run("ResumesFromProposalAfterFailedTxIncluded", func(t *testing.T) {
+		oracle, txMgr, contract, leaves := setup(t)
+		// The second tx fails but was included so the proposal has the first four leaves.
+		txMgr.failAt = 2
+		contract.metadata = contracts.LargePreimageMetaData{ClaimedSize: 1, BlocksProcessed: 4}
+		added, txHashes, err := oracle.addLargePreimageLeafs(context.Background(), common.Hash{0xaa}, big.NewInt(1), 0, leaves)
+		require.NoError(t, err)
+		require.Equal(t, 6, added)
+		require.Len(t, txHashes, 2)
+		require.Equal(t, 3, txMgr.sends)
+		require.Equal(t, 1, contract.metadataCalls)
+		// The leaves in the failed tx are not sent again.
+		require.Equal(t, []uint64{0, 2, 4}, sentFirstLeafIndices(txMgr.candidates))
+	})
+
+	t.Run("ResendsFailedTxNotIncluded", func(t *testing.T) {
+		oracle, txMgr, contract, leaves := setup(t)
+		txMgr.failAt = 2
+		contract.metadata = contracts.LargePreimageMetaData{ClaimedSize: 1, BlocksProcessed: 2}
+		added, _, err := oracle.addLargePreimageLeafs(context.Background(), common.Hash{0xaa}, big.NewInt(1), 0, leaves)
+		require.NoError(t, err)
+		require.Equal(t, 6, added)
+		require.Equal(t, []uint64{0, 2, 2, 4}, sentFirstLeafIndices(txMgr.candidates))
+	})
+
+	t.Run("StopsWhenFinalTxIncluded", func(t *testing.T) {
+		oracle, txMgr, contract, leaves := setup(t)
+		txMgr.failAt = 3
+		contract.metadata = contracts.LargePreimageMetaData{ClaimedSize: 1, BlocksProcessed: 6, Timestamp: 1234}
+		added, _, err := oracle.addLargePreimageLeafs(context.Background(), common.Hash{0xaa}, big.NewInt(1), 0, leaves)
+		require.NoError(t, err)
+		require.Equal(t, 6, added)
+		require.Equal(t, 3, txMgr.sends)
+	})
+
+	t.Run("MetadataFails", func(t *testing.T) {
+		oracle, txMgr, contract, leaves := setup(t)
+		txMgr.failAt = 2
+		contract.metadataFails = true
+		added, _, err := oracle.addLargePreimageLeafs(context.Background(), common.Hash{0xaa}, big.NewInt(1), 0, leaves)
+		require.ErrorIs(t, err, mockProposalMetadataError)
+		require.Equal(t, 2, added)
+		require.Equal(t, 2, txMgr.sends)
+	})
+
+	t.Run("UnexpectedLeafCount", func(t *testing.T) {
+		oracle, txMgr, contract, leaves := setup(t)
+		txMgr.failAt = 2
+		contract.metadata = contracts.LargePreimageMetaData{ClaimedSize: 1, BlocksProcessed: 7}
+		_, _, err := oracle.addLargePreimageLeafs(context.Background(), common.Hash{0xaa}, big.NewInt(1), 0, leaves)
+		require.ErrorContains(t, err, "has 7 leaves")
+		require.Equal(t, 2, txMgr.sends)
+	})
+
+	t.Run("AttemptsExhausted", func(t *testing.T) {
+		oracle, _, contract, leaves := setup(t)
+		txMgr := &flakyTxMgr{failures: 100}
+		oracle.txMgr = txMgr
+		contract.metadata = contracts.LargePreimageMetaData{ClaimedSize: 1}
+		_, _, err := oracle.addLargePreimageLeafs(context.Background(), common.Hash{0xaa}, big.NewInt(1), 0, leaves)
+		require.ErrorIs(t, err, mockTxMgrSendError)
+		require.Equal(t, defaultMaxSendAttempts, txMgr.sends)
+	})
+
+	t.Run("EstimationRevertNotRetried", func(t *testing.T) {
+		oracle, _, contract, leaves := setup(t)
+		txMgr := &flakyTxMgr{failures: 1, err: errors.New("failed to estimate gas: execution reverted")}
+		oracle.txMgr = txMgr
+		_, _, err := oracle.addLargePreimageLeafs(context.Background(), common.Hash{0xaa}, big.NewInt(1), 0, leaves)
+		require.ErrorContains(t, err, "execution reverted")
+		require.Equal(t, 1, txMgr.sends)
+		require.Equal(t, 0, contract.metadataCalls)
+	})
+}
+
+// notIncluded reports that the tx is never included by a failed send.
+func notIncluded(_ context.Context) (bool, error) {
+	return false, nil
+}
+
+// sentFirstLeafIndices returns the index of the first leaf added by each tx created by
+// mockPreimageOracleContract.AddLeaves.
+func sentFirstLeafIndices(candidates []txmgr.TxCandidate) []uint64 {
+	indices := make([]uint64, 0, len(candidates))
+	for _, candidate := range candidates {
+		indices = append(indices, firstLeafIndex(candidate))
+	}
+	return indices
+}
+
+// flakyTxMgr fails the first failures sends with err, or a transient error if not set, and then succeeds.
+type flakyTxMgr struct {
+	mockTxMgr
+	failures int
+	err      error
+}
+
+func (s *flakyTxMgr) Send(ctx context.Context, candidate txmgr.TxCandidate) (*ethtypes.Receipt, error) {
+	if s.sends < s.failures {
+		s.sends++
+		s.candidates = append(s.candidates, candidate)
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, mockTxMgrSendError
+	}
+	return s.mockTxMgr.Send(ctx, candidate)
+}
+
+// blockingTxMgr blocks each send until it is released, tracking the number of sends in flight.
+type blockingTxMgr struct {
+	mockTxMgr
+	lock        sync.Mutex
+	pending     int
+	maxInFlight int
+	// failAt is the 1-based index of the send that fails. Zero if no sends fail.
+	failAt  int
+	allowed chan struct{}
+}
+
+func newBlockingTxMgr() *blockingTxMgr {
+	return &blockingTxMgr{allowed: make(chan struct{}, 100)}
+}
+
+func (s *blockingTxMgr) release(count int) {
+	for i := 0; i < count; i++ {
+		s.allowed <- struct{}{}
+	}
+}
+
+func (s *blockingTxMgr) sent() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.sends
+}
+
+func (s *blockingTxMgr) inFlight() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.pending
+}
+
+func (s *blockingTxMgr) Send(ctx context.Context, candidate txmgr.TxCandidate) (*ethtypes.Receipt, error) {
+	s.lock.Lock()
+	s.sends++
+	s.candidates = append(s.candidates, candidate)
+	send := s.sends
+	s.pending++
+	s.maxInFlight = max(s.maxInFlight, s.pending)
+	s.lock.Unlock()
+	defer func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.pending--
+	}()
+
+	select {
+	case <-s.allowed:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if send == s.failAt {
+		return nil, mockTxMgrSendError
+	}
+	return &ethtypes.Receipt{Status: ethtypes.ReceiptStatusSuccessful}, nil
+}
+
+type mockPreimageOracleContract struct {
+	initCalls     int
+	initFails     bool
+	addCalls      int
+	addFails      bool
+	txLeaves      []int
+	leaves        []contracts.Leaf
+	finalized     []bool
+	metadata      contracts.LargePreimageMetaData
+	metadataFails bool
+	// metadataResponses are returned in order by successive calls to GetProposalMetadata before metadata is used.
+	metadataResponses []contracts.LargePreimageMetaData
+	metadataCalls     int
+
+	challengePeriod      uint64
+	challengePeriodFails bool
+
+	maxProposalSize      uint64
+	maxProposalSizeFails bool
+
+	treeRootCalls int
+	treeRoot      common.Hash
+	treeRootFails bool
+
+	squeezedCalls int
+	squeezed      bool
+	squeezedFails bool
+	// squeezedFn optionally provides the result of each call to IsProposalSqueezed, overriding squeezed.
+	squeezedFn func() bool
+	// metadataFn optionally provides the result of each call to GetProposalMetadata, overriding metadata.
+	metadataFn func() contracts.LargePreimageMetaData
+
+	squeezeCalls   int
+	squeezeFails   bool
+	stateMatrix    matrix.StateSnapshot
+	preState       contracts.Leaf
+	preStateProof  merkle.Proof
+	postState      contracts.Leaf
+	postStateProof merkle.Proof
+}
+
+func (s *mockPreimageOracleContract) InitLargePreimage(_ *big.Int, _ uint32, _ uint32) (txmgr.TxCandidate, error) {
+	s.initCalls++
+	if s.initFails {
+		return txmgr.TxCandidate{}, mockInitLPPError
+	}
+	return txmgr.TxCandidate{}, nil
+}
+
+func (s *mockPreimageOracleContract) AddLeaves(_ *big.Int, partOffset uint32, leaves []contracts.Leaf, finalize bool, maxLeavesPerTx int) ([]txmgr.TxCandidate, error) {
+	s.addCalls++
+	if s.addFails {
+		return nil, mockAddLeavesError
+	}
+	batches, err := contracts.SplitLeaves(partOffset, leaves, finalize, maxLeavesPerTx)
+	if err != nil {
+		return nil, err
+	}
+	s.leaves = append(s.leaves, leaves...)
+	s.finalized = append(s.finalized, finalize)
+	var txs []txmgr.TxCandidate
+	for _, batch := range batches {
+		s.txLeaves = append(s.txLeaves, len(batch))
+		// Identify each tx by the index of its first leaf so the order they're sent in can be checked.
+		txs = append(txs, txmgr.TxCandidate{TxData: binary.BigEndian.AppendUint64(nil, batch[0].Index.Uint64())})
+	}
+	return txs, nil
+}
+
+// firstLeafIndex returns the index of the first leaf added by a tx created by mockPreimageOracleContract.AddLeaves.
+func firstLeafIndex(candidate txmgr.TxCandidate) uint64 {
+	return binary.BigEndian.Uint64(candidate.TxData)
+}
+
+func (s *mockPreimageOracleContract) Squeeze(_ contracts.LargePreimageIdentifier, stateMatrix matrix.StateSnapshot, preState contracts.Leaf, preStateProof merkle.Proof, postState contracts.Leaf, postStateProof merkle.Proof) (txmgr.TxCandidate, error) {
+	s.squeezeCalls++
+	if s.squeezeFails {
+		return txmgr.TxCandidate{}, mockSqueezeError
+	}
+	s.stateMatrix = stateMatrix
+	s.preState = preState
+	s.preStateProof = preStateProof
+	s.postState = postState
+	s.postStateProof = postStateProof
+	return txmgr.TxCandidate{}, nil
+}
+
+func (s *mockPreimageOracleContract) ChallengePeriod(_ context.Context) (uint64, error) {
+	if s.challengePeriodFails {
+		return 0, mockChallengePeriodError
+	}
+	return s.challengePeriod, nil
+}
+
+func (s *mockPreimageOracleContract) MaxProposalSize(_ context.Context) (uint64, error) {
+	if s.maxProposalSizeFails {
+		return 0, mockMaxProposalSizeError
+	}
+	return s.maxProposalSize, nil
+}
+
+func (s *mockPreimageOracleContract) IsProposalSqueezed(_ context.Context, _ batching.Block, _ contracts.LargePreimageIdentifier, _ common.Hash) (bool, error) {
+	s.squeezedCalls++
+	if s.squeezedFails {
+		return false, mockIsSqueezedError
+	}
+	if s.squeezedFn != nil {
+		return s.squeezedFn(), nil
+	}
+	return s.squeezed, nil
+}
+
+func (s *mockPreimageOracleContract) GetProposalTreeRoot(_ context.Context, _ batching.Block, _ contracts.LargePreimageIdentifier) (common.Hash, error) {
+	s.treeRootCalls++
+	if s.treeRootFails {
+		return common.Hash{}, mockTreeRootError
+	}
+	return s.treeRoot, nil
+}
+
+func (s *mockPreimageOracleContract) GetProposalMetadata(_ context.Context, _ batching.Block, ident contracts.LargePreimageIdentifier) (contracts.LargePreimageMetaData, error) {
+	s.metadataCalls++
+	if s.metadataFails {
+		return contracts.LargePreimageMetaData{}, mockProposalMetadataError
+	}
+	metadata := s.metadata
+	if s.metadataFn != nil {
+		metadata = s.metadataFn()
+	}
+	if len(s.metadataResponses) > 0 {
+		metadata = s.metadataResponses[0]
+		s.metadataResponses = s.metadataResponses[1:]
+	}
+	metadata.LargePreimageIdentifier = ident
+	return metadata, nil
 }
@@ -0,0 +1,214 @@
+package preimages
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/types"
+	"github.com/ethereum-optimism/optimism/op-service/clock"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// UploadJob is a preimage to upload with the index of the claim it is required for.
+type UploadJob struct {
+	Parent uint64
+	Data   *types.PreimageOracleData
+}
+
+// UploadResult is the outcome of an UploadJob.
+type UploadResult struct {
+	Job      UploadJob
+	TxHashes []common.Hash
+	Err      error
+}
+
+// UploadScheduler limits how many preimage uploads run at once and ensures the same preimage is never uploaded by
+// two uploads at once.
+// A single scheduler is shared by all uploaders it limits so the limits apply across games.
+// Use a txmgr created with NewPacedTxManager to also limit the rate uploaders send transactions.
+type UploadScheduler struct {
+	log   log.Logger
+	slots chan struct{}
+
+	lock    sync.Mutex
+	uploads map[uploadKey]*uploadLock
+}
+
+// uploadKey identifies the preimage part being uploaded.
+// The large preimage proposal uuid is derived from the key, offset and size of the preimage, and the oracle doesn't
+// prevent a proposal being initialized twice, so concurrent uploads of the same part would corrupt the proposal.
+type uploadKey struct {
+	key    common.Hash
+	offset uint32
+}
+
+// uploadLock is held by the upload of a preimage part. refs counts the uploads holding or waiting for the lock.
+type uploadLock struct {
+	held chan struct{}
+	refs int
+}
+
+// NewUploadScheduler creates a scheduler that runs at most maxConcurrentUploads uploads at once.
+func NewUploadScheduler(logger log.Logger, maxConcurrentUploads int) *UploadScheduler {
+	return &UploadScheduler{
+		log:     logger,
+		slots:   make(chan struct{}, max(maxConcurrentUploads, 1)),
+		uploads: make(map[uploadKey]*uploadLock),
+	}
+}
+
+// Limit returns a PreimageUploader that uploads with uploader, waiting until no other upload of the same preimage is
+// running and the scheduler has capacity before each upload starts.
+func (s *UploadScheduler) Limit(uploader PreimageUploader) PreimageUploader {
+	return &limitedUploader{scheduler: s, uploader: uploader}
+}
+
+// Upload uploads the preimages of jobs with uploader, starting them in order, and returns the result of each job
+// in the same order as jobs. A failed upload doesn't prevent the remaining jobs from running. Jobs not started
+// before ctx is done fail with the context error.
+// A job for a preimage that is already being uploaded waits for that upload to complete before it, and the jobs after
+// it, start.
+func (s *UploadScheduler) Upload(ctx context.Context, uploader PreimageUploader, jobs []UploadJob) []UploadResult {
+	results := make([]UploadResult, len(jobs))
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		results[i].Job = job
+		// Always lock the preimage before acquiring capacity, as limitedUploader does, to avoid deadlocks.
+		unlock, err := s.lockPreimage(ctx, job.Data)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if err := s.acquire(ctx); err != nil {
+			unlock()
+			results[i].Err = err
+			continue
+		}
+		wg.Add(1)
+		go func(result *UploadResult) {
+			defer wg.Done()
+			defer s.release()
+			defer unlock()
+			result.TxHashes, result.Err = uploader.UploadPreimage(ctx, result.Job.Parent, result.Job.Data)
+			if errors.Is(result.Err, ErrChallengePeriodNotOver) {
+				s.log.Debug("Skipping preimage squeeze until challenge period is over", "parent", result.Job.Parent, "key", common.Bytes2Hex(result.Job.Data.OracleKey))
+			} else if result.Err != nil {
+				s.log.Warn("Failed to upload preimage", "parent", result.Job.Parent, "key", common.Bytes2Hex(result.Job.Data.OracleKey), "err", result.Err)
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+	return results
+}
+
+func (s *UploadScheduler) acquire(ctx context.Context) error {
+	// Check ctx first so a done context is reported even if a slot is free.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *UploadScheduler) release() {
+	<-s.slots
+}
+
+// lockPreimage waits until no other upload of the preimage part of data is running, then locks it.
+// Returns the function to call to unlock it once the upload is complete.
+func (s *UploadScheduler) lockPreimage(ctx context.Context, data *types.PreimageOracleData) (func(), error) {
+	key := uploadKey{key: common.BytesToHash(data.OracleKey), offset: data.OracleOffset}
+	s.lock.Lock()
+	l, ok := s.uploads[key]
+	if !ok {
+		l = &uploadLock{held: make(chan struct{}, 1)}
+		s.uploads[key] = l
+	}
+	l.refs++
+	s.lock.Unlock()
+
+	unref := func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		l.refs--
+		if l.refs == 0 {
+			delete(s.uploads, key)
+		}
+	}
+	select {
+	case l.held <- struct{}{}:
+		return func() {
+			<-l.held
+			unref()
+		}, nil
+	case <-ctx.Done():
+		unref()
+		return nil, ctx.Err()
+	}
+}
+
+type limitedUploader struct {
+	scheduler *UploadScheduler
+	uploader  PreimageUploader
+}
+
+func (u *limitedUploader) UploadPreimage(ctx context.Context, claimIdx uint64, data *types.PreimageOracleData) ([]common.Hash, error) {
+	// Wait for other uploads of the same preimage first so waiting doesn't use up the scheduler's capacity.
+	unlock, err := u.scheduler.lockPreimage(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if err := u.scheduler.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer u.scheduler.release()
+	return u.uploader.UploadPreimage(ctx, claimIdx, data)
+}
+
+// NewPacedTxManager creates a txmgr that sends with txMgr, delaying sends so that at least interval elapses
+// between the start of each send. A zero interval sends transactions as soon as they are ready.
+func NewPacedTxManager(txMgr txmgr.TxManager, cl clock.Clock, interval time.Duration) txmgr.TxManager {
+	if interval <= 0 {
+		return txMgr
+	}
+	return &pacedTxManager{TxManager: txMgr, clock: cl, interval: interval}
+}
+
+type pacedTxManager struct {
+	txmgr.TxManager
+	clock    clock.Clock
+	interval time.Duration
+
+	lock     sync.Mutex
+	nextSend time.Time
+}
+
+func (m *pacedTxManager) Send(ctx context.Context, candidate txmgr.TxCandidate) (*ethtypes.Receipt, error) {
+	m.lock.Lock()
+	now := m.clock.Now()
+	sendAt := now
+	if m.nextSend.After(now) {
+		sendAt = m.nextSend
+	}
+	m.nextSend = sendAt.Add(m.interval)
+	m.lock.Unlock()
+
+	if delay := sendAt.Sub(now); delay > 0 {
+		select {
+		case <-m.clock.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return m.TxManager.Send(ctx, candidate)
+}
@@ -0,0 +1,345 @@
+package preimages
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/contracts"
+	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/types"
+	"github.com/ethereum-optimism/optimism/op-service/clock"
+	"github.com/ethereum-optimism/optimism/op-service/testlog"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+var mockUploadError = errors.New("mock upload error")
+
+func TestUploadScheduler_Upload(t *testing.T) {
+	t.Run("LimitsConcurrency", func(t *testing.T) {
+		uploader := newBlockingUploader()
+		scheduler := NewUploadScheduler(testlog.Logger(t, log.LvlError), 3)
+		jobs := newUploadJobs(6)
+
+		done := make(chan []UploadResult, 1)
+		go func() {
+			done <- scheduler.Upload(context.Background(), uploader, jobs)
+		}()
+
+		// The first three uploads must all be in flight before any of them completes.
+		uploader.requireStarted(t, 0, 1, 2)
+		uploader.requireActive(t, 3)
+		for i := 3; i < len(jobs); i++ {
+			uploader.release <- struct{}{}
+			uploader.requireStarted(t, uint64(i))
+			uploader.requireActive(t, 3)
+		}
+		for i := 0; i < 3; i++ {
+			uploader.release <- struct{}{}
+		}
+
+		results := <-done
+		require.Len(t, results, len(jobs))
+		for i, result := range results {
+			require.NoError(t, result.Err)
+			require.Equal(t, jobs[i], result.Job)
+			require.Equal(t, []common.Hash{{byte(i)}}, result.TxHashes)
+		}
+		require.Equal(t, 3, uploader.maxActive)
+	})
+
+	t.Run("Sequential", func(t *testing.T) {
+		uploader := newBlockingUploader()
+		scheduler := NewUploadScheduler(testlog.Logger(t, log.LvlError), 1)
+		jobs := newUploadJobs(3)
+
+		done := make(chan []UploadResult, 1)
+		go func() {
+			done <- scheduler.Upload(context.Background(), uploader, jobs)
+		}()
+		for i := range jobs {
+			uploader.requireStarted(t, uint64(i))
+			uploader.requireActive(t, 1)
+			uploader.release <- struct{}{}
+		}
+		<-done
+		require.Equal(t, 1, uploader.maxActive)
+	})
+
+	t.Run("SamePreimageNotConcurrent", func(t *testing.T) {
+		uploader := newBlockingUploader()
+		scheduler := NewUploadScheduler(testlog.Logger(t, log.LvlError), 3)
+		jobs := newUploadJobs(3)
+		jobs[1].Data = jobs[0].Data
+
+		done := make(chan []UploadResult, 1)
+		go func() {
+			done <- scheduler.Upload(context.Background(), uploader, jobs)
+		}()
+		// The second job waits for the first, which uploads the same preimage, and the third job starts after it.
+		uploader.requireStarted(t, 0)
+		uploader.release <- struct{}{}
+		uploader.requireStarted(t, 1, 2)
+		uploader.release <- struct{}{}
+		uploader.release <- struct{}{}
+		results := <-done
+		for _, result := range results {
+			require.NoError(t, result.Err)
+		}
+	})
+
+	t.Run("FailureDoesNotStopOtherJobs", func(t *testing.T) {
+		uploader := &stubUploader{errs: map[uint64]error{1: mockUploadError}}
+		scheduler := NewUploadScheduler(testlog.Logger(t, log.LvlError), 1)
+		results := scheduler.Upload(context.Background(), uploader, newUploadJobs(3))
+		require.NoError(t, results[0].Err)
+		require.ErrorIs(t, results[1].Err, mockUploadError)
+		require.NoError(t, results[2].Err)
+		require.Equal(t, 3, uploader.calls)
+	})
+
+	t.Run("ChallengePeriodNotOverLoggedAtDebug", func(t *testing.T) {
+		logger := testlog.Logger(t, log.LvlError)
+		logs := testlog.Capture(logger)
+		uploader := &stubUploader{errs: map[uint64]error{0: ErrChallengePeriodNotOver}}
+		scheduler := NewUploadScheduler(logger, 1)
+		results := scheduler.Upload(context.Background(), uploader, newUploadJobs(1))
+		require.ErrorIs(t, results[0].Err, ErrChallengePeriodNotOver)
+		require.Nil(t, logs.FindLog(log.LvlWarn, "Failed to upload preimage"))
+		require.NotNil(t, logs.FindLog(log.LvlDebug, "Skipping preimage squeeze until challenge period is over"))
+	})
+
+	t.Run("ContextDone", func(t *testing.T) {
+		uploader := &stubUploader{}
+		scheduler := NewUploadScheduler(testlog.Logger(t, log.LvlError), 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		results := scheduler.Upload(ctx, uploader, newUploadJobs(1))
+		require.ErrorIs(t, results[0].Err, context.Canceled)
+		require.Zero(t, uploader.calls)
+	})
+}
+
+func TestUploadScheduler_Limit(t *testing.T) {
+	t.Run("SharesLimitAcrossUploaders", func(t *testing.T) {
+		uploader := newBlockingUploader()
+		scheduler := NewUploadScheduler(testlog.Logger(t, log.LvlError), 1)
+		limited1 := scheduler.Limit(uploader)
+		limited2 := scheduler.Limit(uploader)
+
+		done := make(chan error, 2)
+		go func() {
+			_, err := limited1.UploadPreimage(context.Background(), 0, testUploadData(0))
+			done <- err
+		}()
+		uploader.requireStarted(t, 0)
+		go func() {
+			_, err := limited2.UploadPreimage(context.Background(), 1, testUploadData(1))
+			done <- err
+		}()
+
+		uploader.release <- struct{}{}
+		require.NoError(t, <-done)
+		uploader.requireStarted(t, 1)
+		uploader.release <- struct{}{}
+		require.NoError(t, <-done)
+		require.Equal(t, 1, uploader.maxActive)
+	})
+
+	t.Run("SamePreimageNotConcurrent", func(t *testing.T) {
+		data := makePreimageData(500, 0)
+		// Two games need the same preimage, each with their own uploader, sharing the same txmgr and oracle.
+		uploader1, _, contract := newTestLargePreimageUploader(t)
+		uploader2, _, _ := newTestLargePreimageUploader(t)
+		txMgr := newBlockingTxMgr()
+		uploader1.txMgr = txMgr
+		uploader2.txMgr = txMgr
+		uploader2.contract = contract
+		// The proposal in the oracle reflects the txs that have been included.
+		var included atomic.Int32
+		contract.challengePeriod = 1000
+		contract.metadataFn = func() contracts.LargePreimageMetaData {
+			switch included.Load() {
+			case 0:
+				return contracts.LargePreimageMetaData{}
+			case 1:
+				return contracts.LargePreimageMetaData{ClaimedSize: uint32(len(data.GetPreimageWithoutSize()))}
+			default:
+				return finalizedMetadata(data, uint64(time.Now().Unix()))
+			}
+		}
+		scheduler := NewUploadScheduler(testlog.Logger(t, log.LvlError), 2)
+
+		done := make(chan error, 2)
+		for _, uploader := range []*LargePreimageUploader{uploader1, uploader2} {
+			limited := scheduler.Limit(uploader)
+			go func() {
+				_, err := limited.UploadPreimage(context.Background(), 0, data)
+				done <- err
+			}()
+		}
+
+		// Include the init tx then the tx adding the leaves, while the other upload is waiting to start.
+		for i := 1; i <= 2; i++ {
+			i := i
+			require.Eventually(t, func() bool { return txMgr.sent() == i && txMgr.inFlight() == 1 }, 10*time.Second, time.Millisecond)
+			included.Store(int32(i))
+			txMgr.release(1)
+		}
+		// Both uploads wait for the challenge period once the leaves are added.
+		require.ErrorIs(t, <-done, ErrChallengePeriodNotOver)
+		require.ErrorIs(t, <-done, ErrChallengePeriodNotOver)
+		require.Equal(t, 1, contract.initCalls)
+		require.Equal(t, 1, contract.addCalls)
+		require.Equal(t, 2, txMgr.sent())
+	})
+
+	t.Run("ContextDone", func(t *testing.T) {
+		uploader := newBlockingUploader()
+		scheduler := NewUploadScheduler(testlog.Logger(t, log.LvlError), 1)
+		go func() {
+			_, _ = scheduler.Limit(uploader).UploadPreimage(context.Background(), 0, testUploadData(0))
+		}()
+		uploader.requireStarted(t, 0)
+		defer func() { uploader.release <- struct{}{} }()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := scheduler.Limit(uploader).UploadPreimage(ctx, 1, testUploadData(1))
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestPacedTxManager(t *testing.T) {
+	t.Run("PacesTxs", func(t *testing.T) {
+		txMgr := &mockTxMgr{}
+		cl := clock.NewDeterministicClock(time.Unix(1000, 0))
+		paced := NewPacedTxManager(txMgr, cl, 10*time.Second)
+
+		_, err := paced.Send(context.Background(), txmgr.TxCandidate{})
+		require.NoError(t, err)
+		require.Equal(t, 1, txMgr.sends)
+
+		sent := make(chan error, 1)
+		go func() {
+			_, err := paced.Send(context.Background(), txmgr.TxCandidate{})
+			sent <- err
+		}()
+		require.True(t, cl.WaitForNewPendingTaskWithTimeout(time.Second))
+
+		// The second tx must wait for the full interval after the first.
+		cl.AdvanceTime(9 * time.Second)
+		select {
+		case <-sent:
+			t.Fatal("tx sent before the min interval elapsed")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		cl.AdvanceTime(time.Second)
+		select {
+		case err := <-sent:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("tx not sent after the min interval elapsed")
+		}
+		require.Equal(t, 2, txMgr.sends)
+	})
+
+	t.Run("ZeroIntervalNotPaced", func(t *testing.T) {
+		txMgr := &mockTxMgr{}
+		require.Same(t, txMgr, NewPacedTxManager(txMgr, clock.SystemClock, 0))
+	})
+
+	t.Run("ContextDone", func(t *testing.T) {
+		txMgr := &mockTxMgr{}
+		cl := clock.NewDeterministicClock(time.Unix(1000, 0))
+		paced := NewPacedTxManager(txMgr, cl, time.Minute)
+		_, err := paced.Send(context.Background(), txmgr.TxCandidate{})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = paced.Send(ctx, txmgr.TxCandidate{})
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 1, txMgr.sends)
+	})
+}
+
+func newUploadJobs(count int) []UploadJob {
+	jobs := make([]UploadJob, count)
+	for i := range jobs {
+		jobs[i] = UploadJob{Parent: uint64(i), Data: testUploadData(byte(i))}
+	}
+	return jobs
+}
+
+// testUploadData returns oracle data with a different key for each id.
+func testUploadData(id byte) *types.PreimageOracleData {
+	return &types.PreimageOracleData{OracleKey: common.Hash{0x02, id}.Bytes()}
+}
+
+type stubUploader struct {
+	calls int
+	errs  map[uint64]error
+}
+
+func (u *stubUploader) UploadPreimage(_ context.Context, parent uint64, _ *types.PreimageOracleData) ([]common.Hash, error) {
+	u.calls++
+	return []common.Hash{{byte(parent)}}, u.errs[parent]
+}
+
+// blockingUploader reports each upload as it starts then blocks it until a value is sent on release.
+type blockingUploader struct {
+	started chan uint64
+	release chan struct{}
+
+	lock      sync.Mutex
+	active    int
+	maxActive int
+}
+
+func newBlockingUploader() *blockingUploader {
+	return &blockingUploader{
+		started: make(chan uint64, 10),
+		release: make(chan struct{}),
+	}
+}
+
+func (u *blockingUploader) UploadPreimage(_ context.Context, parent uint64, _ *types.PreimageOracleData) ([]common.Hash, error) {
+	u.lock.Lock()
+	u.active++
+	u.maxActive = max(u.maxActive, u.active)
+	u.lock.Unlock()
+	u.started <- parent
+
+	<-u.release
+
+	u.lock.Lock()
+	u.active--
+	u.lock.Unlock()
+	return []common.Hash{{byte(parent)}}, nil
+}
+
+func (u *blockingUploader) requireStarted(t *testing.T, parents ...uint64) {
+	var started []uint64
+	for range parents {
+		select {
+		case parent := <-u.started:
+			started = append(started, parent)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for uploads %v to start, started: %v", parents, started)
+		}
+	}
+	require.ElementsMatch(t, parents, started)
+}
+
+func (u *blockingUploader) requireActive(t *testing.T, expected int) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	require.Equal(t, expected, u.active)
+}
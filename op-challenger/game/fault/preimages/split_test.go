@@ -4,14 +4,17 @@ import (
 	"context"
 	"testing"
 
+	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/contracts"
 	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/types"
+	"github.com/ethereum-optimism/optimism/op-challenger/game/keccak/matrix"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
 
 func TestSplitPreimageUploader_UploadPreimage(t *testing.T) {
 	t.Run("DirectUploadSucceeds", func(t *testing.T) {
 		oracle, direct, large := newTestSplitPreimageUploader(t)
-		err := oracle.UploadPreimage(context.Background(), 0, &types.PreimageOracleData{})
+		_, err := oracle.UploadPreimage(context.Background(), 0, &types.PreimageOracleData{})
 		require.NoError(t, err)
 		require.Equal(t, 1, direct.updates)
 		require.Equal(t, 0, large.updates)
@@ -19,15 +22,39 @@ func TestSplitPreimageUploader_UploadPreimage(t *testing.T) {
 
 	t.Run("LargeUploadSucceeds", func(t *testing.T) {
 		oracle, direct, large := newTestSplitPreimageUploader(t)
-		err := oracle.UploadPreimage(context.Background(), 0, &types.PreimageOracleData{OracleData: make([]byte, PREIMAGE_SIZE_THRESHOLD+1)})
+		_, err := oracle.UploadPreimage(context.Background(), 0, &types.PreimageOracleData{OracleData: make([]byte, contracts.MaxDirectPreimageSize+1)})
 		require.NoError(t, err)
 		require.Equal(t, 1, large.updates)
 		require.Equal(t, 0, direct.updates)
 	})
 
+	t.Run("MaxDirectPreimageSize", func(t *testing.T) {
+		oracle, direct, large := newTestSplitPreimageUploader(t)
+		_, err := oracle.UploadPreimage(context.Background(), 0, &types.PreimageOracleData{OracleData: make([]byte, contracts.MaxDirectPreimageSize)})
+		require.NoError(t, err)
+		require.Equal(t, 1, direct.updates)
+		require.Equal(t, 0, large.updates)
+	})
+
+	t.Run("SmallPreimageUsesDirectUpload", func(t *testing.T) {
+		oracle, direct, large := newTestSplitPreimageUploader(t)
+		_, err := oracle.UploadPreimage(context.Background(), 0, makePreimageData(20, 0))
+		require.NoError(t, err)
+		require.Equal(t, 1, direct.updates)
+		require.Equal(t, 0, large.updates)
+	})
+
+	t.Run("MultiBlockPreimageUsesLargeUpload", func(t *testing.T) {
+		oracle, direct, large := newTestSplitPreimageUploader(t)
+		_, err := oracle.UploadPreimage(context.Background(), 0, makePreimageData(contracts.MaxDirectPreimageSize+matrix.LeafSize, 0))
+		require.NoError(t, err)
+		require.Equal(t, 0, direct.updates)
+		require.Equal(t, 1, large.updates)
+	})
+
 	t.Run("NilPreimageOracleData", func(t *testing.T) {
 		oracle, _, _ := newTestSplitPreimageUploader(t)
-		err := oracle.UploadPreimage(context.Background(), 0, nil)
+		_, err := oracle.UploadPreimage(context.Background(), 0, nil)
 		require.ErrorIs(t, err, ErrNilPreimageData)
 	})
 }
@@ -37,12 +64,12 @@ type mockPreimageUploader struct {
 	uploadFails bool
 }
 
-func (s *mockPreimageUploader) UploadPreimage(ctx context.Context, parent uint64, data *types.PreimageOracleData) error {
+func (s *mockPreimageUploader) UploadPreimage(ctx context.Context, parent uint64, data *types.PreimageOracleData) ([]common.Hash, error) {
 	s.updates++
 	if s.uploadFails {
-		return mockUpdateOracleTxError
+		return nil, mockUpdateOracleTxError
 	}
-	return nil
+	return nil, nil
 }
 
 func newTestSplitPreimageUploader(t *testing.T) (*SplitPreimageUploader, *mockPreimageUploader, *mockPreimageUploader) {
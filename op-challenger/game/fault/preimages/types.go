@@ -3,9 +3,16 @@ package preimages
 import (
 	"context"
 	"fmt"
+	"math/big"
 
+	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/contracts"
 	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/types"
+	"github.com/ethereum-optimism/optimism/op-challenger/game/keccak/matrix"
+	"github.com/ethereum-optimism/optimism/op-challenger/game/keccak/merkle"
+	"github.com/ethereum-optimism/optimism/op-service/sources/batching"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 )
 
 var ErrNilPreimageData = fmt.Errorf("cannot upload nil preimage data")
@@ -13,10 +20,43 @@ var ErrNilPreimageData = fmt.Errorf("cannot upload nil preimage data")
 // PreimageUploader is responsible for posting preimages.
 type PreimageUploader interface {
 	// UploadPreimage uploads the provided preimage.
-	UploadPreimage(ctx context.Context, claimIdx uint64, data *types.PreimageOracleData) error
+	// Returns the hashes of the transactions sent, in the order they were sent.
+	UploadPreimage(ctx context.Context, claimIdx uint64, data *types.PreimageOracleData) ([]common.Hash, error)
+}
+
+// PreimageGameContract is the interface for interacting with the FaultDisputeGame contract.
+type PreimageGameContract interface {
+	UpdateOracleTx(ctx context.Context, claimIdx uint64, data *types.PreimageOracleData) (txmgr.TxCandidate, error)
+}
+
+// L1HeaderSource provides L1 block headers.
+type L1HeaderSource interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error)
 }
 
 // PreimageOracleContract is the interface for interacting with the PreimageOracle contract.
 type PreimageOracleContract interface {
-	UpdateOracleTx(ctx context.Context, claimIdx uint64, data *types.PreimageOracleData) (txmgr.TxCandidate, error)
+	InitLargePreimage(uuid *big.Int, partOffset uint32, claimedSize uint32) (txmgr.TxCandidate, error)
+	AddLeaves(uuid *big.Int, partOffset uint32, leaves []contracts.Leaf, finalize bool, maxLeavesPerTx int) ([]txmgr.TxCandidate, error)
+	Squeeze(ident contracts.LargePreimageIdentifier, stateMatrix matrix.StateSnapshot, preState contracts.Leaf, preStateProof merkle.Proof, postState contracts.Leaf, postStateProof merkle.Proof) (txmgr.TxCandidate, error)
+	ChallengePeriod(ctx context.Context) (uint64, error)
+	MaxProposalSize(ctx context.Context) (uint64, error)
+	GetProposalMetadata(ctx context.Context, block batching.Block, ident contracts.LargePreimageIdentifier) (contracts.LargePreimageMetaData, error)
+	GetProposalTreeRoot(ctx context.Context, block batching.Block, ident contracts.LargePreimageIdentifier) (common.Hash, error)
+	IsProposalSqueezed(ctx context.Context, block batching.Block, ident contracts.LargePreimageIdentifier, digest common.Hash) (bool, error)
+}
+
+// StateMatrix is the keccak state matrix the preimage is absorbed into to create the proposal leaves.
+type StateMatrix interface {
+	AbsorbLeaf(data []byte, final bool)
+	StateSnapshot() matrix.StateSnapshot
+	Leaves() []contracts.Leaf
+}
+
+// StateMatrixFactory creates a new, empty StateMatrix.
+type StateMatrixFactory func() StateMatrix
+
+// newStateMatrix is the default StateMatrixFactory, creating a real keccak state matrix.
+func newStateMatrix() StateMatrix {
+	return matrix.NewStateMatrix()
 }
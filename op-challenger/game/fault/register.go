@@ -6,12 +6,14 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-challenger/config"
 	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/contracts"
+	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/preimages"
 	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/trace/cannon"
 	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/trace/outputs"
 	faultTypes "github.com/ethereum-optimism/optimism/op-challenger/game/fault/types"
 	"github.com/ethereum-optimism/optimism/op-challenger/game/scheduler"
 	"github.com/ethereum-optimism/optimism/op-challenger/game/types"
 	"github.com/ethereum-optimism/optimism/op-challenger/metrics"
+	"github.com/ethereum-optimism/optimism/op-service/clock"
 	"github.com/ethereum-optimism/optimism/op-service/sources/batching"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -37,11 +39,17 @@ func RegisterGameTypes(
 	cfg *config.Config,
 	rollupClient outputs.OutputRollupClient,
 	txMgr txmgr.TxManager,
+	l1Head preimages.L1HeaderSource,
 	gameFactory *contracts.DisputeGameFactoryContract,
 	caller *batching.MultiCaller,
 ) (CloseFunc, error) {
 	var closer CloseFunc
 	var l2Client *ethclient.Client
+	oracles := contracts.NewOracleRegistry(caller)
+	// Large preimage uploads are limited and paced across all games so they can't flood the txmgr and mempool.
+	// Sharing the scheduler also ensures games that need the same preimage don't upload it at the same time.
+	uploads := preimages.NewUploadScheduler(logger, int(cfg.MaxPreimageUploads))
+	preimageTxMgr := preimages.NewPacedTxManager(txMgr, clock.SystemClock, cfg.PreimageTxInterval)
 	if cfg.TraceTypeEnabled(config.TraceTypeCannon) {
 		l2, err := ethclient.DialContext(ctx, cfg.CannonL2)
 		if err != nil {
@@ -51,12 +59,12 @@ func RegisterGameTypes(
 		closer = l2Client.Close
 	}
 	if cfg.TraceTypeEnabled(config.TraceTypeCannon) {
-		if err := registerCannon(registry, ctx, logger, m, cfg, rollupClient, txMgr, gameFactory, caller, l2Client); err != nil {
+		if err := registerCannon(registry, ctx, logger, m, cfg, rollupClient, txMgr, l1Head, uploads, preimageTxMgr, gameFactory, caller, oracles, l2Client); err != nil {
 			return nil, fmt.Errorf("failed to register cannon game type: %w", err)
 		}
 	}
 	if cfg.TraceTypeEnabled(config.TraceTypeAlphabet) {
-		if err := registerAlphabet(registry, ctx, logger, m, rollupClient, txMgr, gameFactory, caller); err != nil {
+		if err := registerAlphabet(registry, ctx, logger, m, rollupClient, txMgr, l1Head, uploads, preimageTxMgr, gameFactory, caller, oracles); err != nil {
 			return nil, fmt.Errorf("failed to register alphabet game type: %w", err)
 		}
 	}
@@ -70,8 +78,12 @@ func registerAlphabet(
 	m metrics.Metricer,
 	rollupClient outputs.OutputRollupClient,
 	txMgr txmgr.TxManager,
+	l1Head preimages.L1HeaderSource,
+	uploads *preimages.UploadScheduler,
+	preimageTxMgr txmgr.TxManager,
 	gameFactory *contracts.DisputeGameFactoryContract,
 	caller *batching.MultiCaller,
+	oracles *contracts.OracleRegistry,
 ) error {
 	playerCreator := func(game types.GameMetadata, dir string) (scheduler.GamePlayer, error) {
 		contract, err := contracts.NewFaultDisputeGameContract(game.Proxy, caller)
@@ -96,9 +108,9 @@ func registerAlphabet(
 		}
 		prestateValidator := NewPrestateValidator(contract.GetAbsolutePrestateHash, prestateProvider)
 		genesisValidator := NewPrestateValidator(contract.GetGenesisOutputRoot, prestateProvider)
-		return NewGamePlayer(ctx, logger, m, dir, game.Proxy, txMgr, contract, []Validator{prestateValidator, genesisValidator}, creator)
+		return NewGamePlayer(ctx, logger, m, dir, game.Proxy, txMgr, l1Head, uploads, preimageTxMgr, oracles, contract, []Validator{prestateValidator, genesisValidator}, creator)
 	}
-	oracle, err := createOracle(ctx, gameFactory, caller)
+	oracle, err := createOracle(ctx, gameFactory, caller, oracles)
 	if err != nil {
 		return err
 	}
@@ -106,7 +118,7 @@ func registerAlphabet(
 	return nil
 }
 
-func createOracle(ctx context.Context, gameFactory *contracts.DisputeGameFactoryContract, caller *batching.MultiCaller) (*contracts.PreimageOracleContract, error) {
+func createOracle(ctx context.Context, gameFactory *contracts.DisputeGameFactoryContract, caller *batching.MultiCaller, oracles *contracts.OracleRegistry) (*contracts.PreimageOracleContract, error) {
 	implAddr, err := gameFactory.GetGameImpl(ctx, alphabetGameType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load alphabet game implementation: %w", err)
@@ -115,11 +127,7 @@ func createOracle(ctx context.Context, gameFactory *contracts.DisputeGameFactory
 	if err != nil {
 		return nil, err
 	}
-	oracle, err := contract.GetOracle(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load oracle address: %w", err)
-	}
-	return oracle, nil
+	return oracles.GameOracle(ctx, contract)
 }
 
 func registerCannon(
@@ -130,8 +138,12 @@ func registerCannon(
 	cfg *config.Config,
 	rollupClient outputs.OutputRollupClient,
 	txMgr txmgr.TxManager,
+	l1Head preimages.L1HeaderSource,
+	uploads *preimages.UploadScheduler,
+	preimageTxMgr txmgr.TxManager,
 	gameFactory *contracts.DisputeGameFactoryContract,
 	caller *batching.MultiCaller,
+	oracles *contracts.OracleRegistry,
 	l2Client cannon.L2HeaderSource,
 ) error {
 	playerCreator := func(game types.GameMetadata, dir string) (scheduler.GamePlayer, error) {
@@ -157,9 +169,9 @@ func registerCannon(
 		}
 		prestateValidator := NewPrestateValidator(contract.GetAbsolutePrestateHash, prestateProvider)
 		genesisValidator := NewPrestateValidator(contract.GetGenesisOutputRoot, prestateProvider)
-		return NewGamePlayer(ctx, logger, m, dir, game.Proxy, txMgr, contract, []Validator{prestateValidator, genesisValidator}, creator)
+		return NewGamePlayer(ctx, logger, m, dir, game.Proxy, txMgr, l1Head, uploads, preimageTxMgr, oracles, contract, []Validator{prestateValidator, genesisValidator}, creator)
 	}
-	oracle, err := createOracle(ctx, gameFactory, caller)
+	oracle, err := createOracle(ctx, gameFactory, caller, oracles)
 	if err != nil {
 		return err
 	}
@@ -79,7 +79,10 @@ func (r *FaultResponder) ResolveClaim(ctx context.Context, claimIdx uint64) erro
 
 func (r *FaultResponder) PerformAction(ctx context.Context, action types.Action) error {
 	if action.OracleData != nil {
-		err := r.uploader.UploadPreimage(ctx, uint64(action.ParentIdx), action.OracleData)
+		txHashes, err := r.uploader.UploadPreimage(ctx, uint64(action.ParentIdx), action.OracleData)
+		if len(txHashes) > 0 {
+			r.log.Info("Sent preimage upload txs", "parentIdx", action.ParentIdx, "tx_hashes", txHashes)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to upload preimage: %w", err)
 		}
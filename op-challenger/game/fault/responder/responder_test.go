@@ -229,12 +229,12 @@ type mockPreimageUploader struct {
 	uploadFails bool
 }
 
-func (m *mockPreimageUploader) UploadPreimage(ctx context.Context, parent uint64, data *types.PreimageOracleData) error {
+func (m *mockPreimageUploader) UploadPreimage(ctx context.Context, parent uint64, data *types.PreimageOracleData) ([]common.Hash, error) {
 	m.updates++
 	if m.uploadFails {
-		return mockPreimageUploadErr
+		return nil, mockPreimageUploadErr
 	}
-	return nil
+	return nil, nil
 }
 
 type mockTxManager struct {
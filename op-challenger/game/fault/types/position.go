@@ -10,6 +10,7 @@ import (
 
 var (
 	ErrPositionDepthTooSmall = errors.New("position depth is too small")
+	ErrDefendRoot            = errors.New("cannot defend the root position")
 )
 
 // Depth is the depth of a position in a game tree where the root level has
@@ -115,6 +116,25 @@ func (p Position) RightOf(parent Position) bool {
 	return p.parentIndexAtDepth().Cmp(parent.IndexAtDepth()) != 0
 }
 
+// Parent returns the position of the parent of this position.
+// Returns ErrPositionDepthTooSmall for the root position, which has no parent.
+func (p Position) Parent() (Position, error) {
+	if p.depth == 0 {
+		return Position{}, ErrPositionDepthTooSmall
+	}
+	return p.parent(), nil
+}
+
+// LeftChild returns the position of the left child of this position.
+func (p Position) LeftChild() Position {
+	return p.move(false)
+}
+
+// RightChild returns the position of the right child of this position.
+func (p Position) RightChild() Position {
+	return p.move(true)
+}
+
 // parent return a new position that is the parent of this Position.
 func (p Position) parent() Position {
 	return Position{
@@ -133,6 +153,23 @@ func (p Position) Defend() Position {
 	return p.parent().move(true).move(false)
 }
 
+// MoveDown returns the position of a move against this position, the attack position if isAttack is true
+// and the defend position otherwise.
+// Returns ErrDefendRoot when defending the root position and ErrGameDepthReached if this position is at or
+// below maxDepth, as those claims can only be countered with a step.
+func (p Position) MoveDown(isAttack bool, maxDepth Depth) (Position, error) {
+	if p.depth >= maxDepth {
+		return Position{}, ErrGameDepthReached
+	}
+	if isAttack {
+		return p.Attack(), nil
+	}
+	if p.depth == 0 {
+		return Position{}, ErrDefendRoot
+	}
+	return p.Defend(), nil
+}
+
 func (p Position) Print(maxDepth Depth) {
 	fmt.Printf("GIN: %4b\tTrace Position is %4b\tTrace Depth is: %d\tTrace Index is: %d\n", p.ToGIndex(), p.indexAtDepth, p.depth, p.TraceIndex(maxDepth))
 }
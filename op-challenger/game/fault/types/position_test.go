@@ -200,6 +200,95 @@ func TestDefend(t *testing.T) {
 	}
 }
 
+func TestMoveDown(t *testing.T) {
+	maxDepth := Depth(3)
+	tests := []struct {
+		name          string
+		startGIndex   *big.Int
+		isAttack      bool
+		expectedIndex *big.Int
+		expectedErr   error
+	}{
+		{"AttackRoot", bi(1), true, bi(2), nil},
+		{"DefendRoot", bi(1), false, nil, ErrDefendRoot},
+		{"AttackLeft", bi(2), true, bi(4), nil},
+		{"DefendLeft", bi(2), false, bi(6), nil},
+		{"AttackRight", bi(3), true, bi(6), nil},
+		{"DefendRight", bi(3), false, bi(6), nil},
+		{"AttackAboveMaxDepth", bi(5), true, bi(10), nil},
+		{"DefendAboveMaxDepth", bi(5), false, bi(10), nil},
+		{"AttackAtMaxDepth", bi(8), true, nil, ErrGameDepthReached},
+		{"DefendAtMaxDepth", bi(15), false, nil, ErrGameDepthReached},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			result, err := NewPositionFromGIndex(test.startGIndex).MoveDown(test.isAttack, maxDepth)
+			if test.expectedErr != nil {
+				require.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedIndex, result.ToGIndex())
+		})
+	}
+}
+
+func TestParent(t *testing.T) {
+	t.Run("RootHasNoParent", func(t *testing.T) {
+		_, err := NewPositionFromGIndex(bi(1)).Parent()
+		require.ErrorIs(t, err, ErrPositionDepthTooSmall)
+	})
+
+	tests := []struct {
+		startGIndex  *big.Int
+		parentGIndex *big.Int
+	}{
+		{bi(2), bi(1)},
+		{bi(3), bi(1)},
+		{bi(4), bi(2)},
+		{bi(5), bi(2)},
+		{bi(6), bi(3)},
+		{bi(7), bi(3)},
+		{bi(14), bi(7)},
+		{bi(15), bi(7)},
+		{new(big.Int).Lsh(bi(1), 73), new(big.Int).Lsh(bi(1), 72)},
+	}
+	for _, test := range tests {
+		result, err := NewPositionFromGIndex(test.startGIndex).Parent()
+		require.NoError(t, err)
+		require.Equalf(t, test.parentGIndex, result.ToGIndex(), "parent of GIndex %s, expected=%s, got=%s", test.startGIndex, test.parentGIndex, result.ToGIndex())
+	}
+}
+
+func TestChildren(t *testing.T) {
+	tests := []struct {
+		startGIndex *big.Int
+		leftGIndex  *big.Int
+		rightGIndex *big.Int
+	}{
+		{bi(1), bi(2), bi(3)},
+		{bi(2), bi(4), bi(5)},
+		{bi(3), bi(6), bi(7)},
+		{bi(7), bi(14), bi(15)},
+		{bi(15), bi(30), bi(31)},
+	}
+	for _, test := range tests {
+		pos := NewPositionFromGIndex(test.startGIndex)
+		left := pos.LeftChild()
+		right := pos.RightChild()
+		require.Equalf(t, test.leftGIndex, left.ToGIndex(), "left child of GIndex %s", test.startGIndex)
+		require.Equalf(t, test.rightGIndex, right.ToGIndex(), "right child of GIndex %s", test.startGIndex)
+
+		leftParent, err := left.Parent()
+		require.NoError(t, err)
+		require.Equal(t, pos.ToGIndex(), leftParent.ToGIndex())
+		rightParent, err := right.Parent()
+		require.NoError(t, err)
+		require.Equal(t, pos.ToGIndex(), rightParent.ToGIndex())
+	}
+}
+
 func TestRelativeToAncestorAtDepth(t *testing.T) {
 	t.Run("ErrorsForDeepAncestor", func(t *testing.T) {
 		pos := NewPosition(1, big.NewInt(1))
@@ -3,19 +3,31 @@ package types
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 
+	"github.com/ethereum-optimism/optimism/op-challenger/game/keccak/matrix"
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
 	"github.com/ethereum/go-ethereum/common"
 )
 
 var (
 	ErrGameDepthReached = errors.New("game depth reached")
 
+	// ErrMissingPreimageSize is returned when the oracle data is too short to contain the preimage size prefix.
+	ErrMissingPreimageSize = errors.New("oracle data too short to contain the preimage size prefix")
+
+	// ErrOracleOffsetOutOfBounds is returned when the oracle offset is beyond the end of the oracle data.
+	ErrOracleOffsetOutOfBounds = errors.New("oracle offset out of bounds")
+
 	// NoLocalContext is the LocalContext value used when the cannon trace provider is used alone instead of as part
 	// of a split game.
 	NoLocalContext = common.Hash{}
 )
 
+// PreimageSizePrefixLength is the length of the big endian preimage size that prefixes the oracle data.
+const PreimageSizePrefixLength = 8
+
 // PreimageOracleData encapsulates the preimage oracle data
 // to load into the onchain oracle.
 type PreimageOracleData struct {
@@ -25,20 +37,62 @@ type PreimageOracleData struct {
 	OracleOffset uint32
 }
 
+// KeyType returns the type of the oracle key, identified by the first byte of the key.
+// Returns the zero key type, which is never valid, if the key is empty.
+func (p *PreimageOracleData) KeyType() preimage.KeyType {
+	return keyType(p.OracleKey)
+}
+
+func keyType(key []byte) preimage.KeyType {
+	if len(key) == 0 {
+		return 0
+	}
+	return preimage.KeyType(key[0])
+}
+
 // GetIdent returns the ident for the preimage oracle data.
 func (p *PreimageOracleData) GetIdent() *big.Int {
 	return new(big.Int).SetBytes(p.OracleKey[1:])
 }
 
 // GetPreimageWithoutSize returns the preimage for the preimage oracle data.
+// Returns nil if the oracle data is too short to contain the size prefix, see CheckPreimageSize.
 func (p *PreimageOracleData) GetPreimageWithoutSize() []byte {
-	return p.OracleData[8:]
+	if len(p.OracleData) < PreimageSizePrefixLength {
+		return nil
+	}
+	return p.OracleData[PreimageSizePrefixLength:]
+}
+
+// CheckPreimageSize returns ErrMissingPreimageSize if the oracle data is too short to contain the size prefix.
+func (p *PreimageOracleData) CheckPreimageSize() error {
+	if len(p.OracleData) < PreimageSizePrefixLength {
+		return fmt.Errorf("%w: got %v bytes", ErrMissingPreimageSize, len(p.OracleData))
+	}
+	return nil
+}
+
+// LastLeafBytes returns the number of preimage bytes in the final leaf when the preimage is absorbed by keccak.
+// The final leaf is always a partial block that is padded when absorbed, so if the preimage is an exact multiple
+// of the block size, the final leaf contains only padding and this returns 0.
+func (p *PreimageOracleData) LastLeafBytes() int {
+	return len(p.GetPreimageWithoutSize()) % matrix.LeafSize
+}
+
+// CheckOracleOffset returns ErrOracleOffsetOutOfBounds if the oracle offset is not within the oracle data.
+// The oracle data includes the size prefix so, matching the oracle contract, the offset must be less than the
+// preimage length plus the length of the prefix.
+func (p *PreimageOracleData) CheckOracleOffset() error {
+	if uint64(p.OracleOffset) >= uint64(len(p.OracleData)) {
+		return fmt.Errorf("%w: offset %v but oracle data is %v bytes", ErrOracleOffsetOutOfBounds, p.OracleOffset, len(p.OracleData))
+	}
+	return nil
 }
 
 // NewPreimageOracleData creates a new [PreimageOracleData] instance.
 func NewPreimageOracleData(key []byte, data []byte, offset uint32) *PreimageOracleData {
 	return &PreimageOracleData{
-		IsLocal:      len(key) > 0 && key[0] == byte(1),
+		IsLocal:      keyType(key) == preimage.LocalKeyType,
 		OracleKey:    key,
 		OracleData:   data,
 		OracleOffset: offset,
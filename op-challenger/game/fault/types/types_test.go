@@ -4,6 +4,9 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/ethereum-optimism/optimism/op-challenger/game/keccak/matrix"
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -25,6 +28,102 @@ func TestNewPreimageOracleData(t *testing.T) {
 	})
 }
 
+func TestLastLeafBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		expected int
+	}{
+		{"Empty", 0, 0},
+		{"SingleByte", 1, 1},
+		{"PartialBlock", 100, 100},
+		{"OneLessThanBlock", matrix.LeafSize - 1, matrix.LeafSize - 1},
+		{"ExactBlock", matrix.LeafSize, 0},
+		{"BlockPlusOne", matrix.LeafSize + 1, 1},
+		{"MultipleBlocks", matrix.LeafSize * 3, 0},
+		{"MultipleBlocksPartial", matrix.LeafSize*3 + 50, 50},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			data := NewPreimageOracleData([]byte{2}, make([]byte, 8+test.size), 0)
+			require.Equal(t, test.expected, data.LastLeafBytes())
+		})
+	}
+}
+
+func TestKeyType(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      []byte
+		expected preimage.KeyType
+		isLocal  bool
+	}{
+		{name: "Empty", key: nil, expected: 0},
+		{name: "Local", key: common.Hash{0x01, 0xaa}.Bytes(), expected: preimage.LocalKeyType, isLocal: true},
+		{name: "Keccak256", key: common.Hash{0x02, 0xaa}.Bytes(), expected: preimage.Keccak256KeyType},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			data := NewPreimageOracleData(test.key, nil, 0)
+			require.Equal(t, test.expected, data.KeyType())
+			require.Equal(t, test.isLocal, data.IsLocal)
+		})
+	}
+}
+
+func TestGetPreimageWithoutSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		expected []byte
+		err      error
+	}{
+		{name: "Empty", size: 0, expected: nil, err: ErrMissingPreimageSize},
+		{name: "PartialSize", size: 7, expected: nil, err: ErrMissingPreimageSize},
+		{name: "SizeOnly", size: 8, expected: []byte{}},
+		{name: "WithPreimage", size: 10, expected: []byte{8, 9}},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			oracleData := make([]byte, test.size)
+			for i := range oracleData {
+				oracleData[i] = byte(i)
+			}
+			data := NewPreimageOracleData([]byte{2}, oracleData, 0)
+			require.Equal(t, test.expected, data.GetPreimageWithoutSize())
+			require.ErrorIs(t, data.CheckPreimageSize(), test.err)
+		})
+	}
+}
+
+func TestCheckOracleOffset(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		offset uint32
+		err    error
+	}{
+		{name: "Start", size: 10, offset: 0},
+		{name: "WithinSizePrefix", size: 10, offset: 7},
+		{name: "WithinPreimage", size: 10, offset: 8},
+		{name: "LastByte", size: 10, offset: 9},
+		{name: "SizePrefixOnly", size: 8, offset: 7},
+		{name: "PastEnd", size: 10, offset: 10, err: ErrOracleOffsetOutOfBounds},
+		{name: "FarPastEnd", size: 10, offset: 1000, err: ErrOracleOffsetOutOfBounds},
+		{name: "EmptyData", size: 0, offset: 0, err: ErrOracleOffsetOutOfBounds},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			data := NewPreimageOracleData([]byte{2}, make([]byte, test.size), test.offset)
+			require.ErrorIs(t, data.CheckOracleOffset(), test.err)
+		})
+	}
+}
+
 func TestIsRootPosition(t *testing.T) {
 	tests := []struct {
 		name     string
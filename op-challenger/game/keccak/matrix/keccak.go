@@ -40,7 +40,7 @@ import (
 // Only use this function if you require compatibility with an existing cryptosystem
 // that uses non-standard padding. All other users should use New256 instead.
 func newLegacyKeccak256() *state {
-	return &state{rate: 136, outputLen: 32, dsbyte: 0x01}
+	return &state{rate: LeafSize, outputLen: 32, dsbyte: 0x01}
 }
 
 var (
@@ -0,0 +1,38 @@
+package matrix
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Leaf is the keccak state matrix added to the large preimage merkle tree.
+type Leaf struct {
+	// Input is the data absorbed for the block.
+	// It is exactly LeafSize bytes, except for the final leaf which may be shorter as it is padded by the contract.
+	Input []byte
+	// Index of the block in the absorption process
+	Index *big.Int
+	// StateCommitment is the hash of the internal state after absorbing the input.
+	StateCommitment common.Hash
+}
+
+// PaddedInput returns the leaf input padded to a full block, as absorbed by the keccak sponge.
+// Only the final leaf requires padding.
+func (l Leaf) PaddedInput() [LeafSize]byte {
+	var padded [LeafSize]byte
+	copy(padded[:], l.Input)
+	if len(l.Input) < LeafSize {
+		// Legacy keccak pad10*1 padding with the 0x01 domain separator.
+		padded[len(l.Input)] ^= 0x01
+		padded[LeafSize-1] ^= 0x80
+	}
+	return padded
+}
+
+// Hash returns the hash of the leaf as added to the large preimage merkle tree by the oracle.
+func (l Leaf) Hash() common.Hash {
+	padded := l.PaddedInput()
+	return crypto.Keccak256Hash(padded[:], common.BigToHash(l.Index).Bytes(), l.StateCommitment.Bytes())
+}
@@ -0,0 +1,48 @@
+package matrix
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaf_PaddedInput(t *testing.T) {
+	for _, size := range []int{0, 1, LeafSize - 1, LeafSize} {
+		size := size
+		t.Run(fmt.Sprintf("Size-%v", size), func(t *testing.T) {
+			input := make([]byte, size)
+			for i := range input {
+				input[i] = byte(i + 1)
+			}
+			leaf := Leaf{Input: input, Index: big.NewInt(1)}
+			padded := leaf.PaddedInput()
+			require.Equal(t, input, padded[:size])
+
+			// Absorbing the padded input must produce the same state as absorbing the raw final input.
+			final := size < LeafSize
+			expected := NewStateMatrix()
+			expected.AbsorbLeaf(input, final)
+			actual := NewStateMatrix()
+			actual.AbsorbLeaf(padded[:], false)
+			require.Equal(t, expected.StateCommitment(), actual.StateCommitment())
+		})
+	}
+}
+
+func TestLeaf_Hash(t *testing.T) {
+	leaf := Leaf{
+		Input:           []byte{0xaa, 0xbb},
+		Index:           big.NewInt(5),
+		StateCommitment: common.Hash{0xcc},
+	}
+	padded := leaf.PaddedInput()
+	var data []byte
+	data = append(data, padded[:]...)
+	data = append(data, common.BigToHash(big.NewInt(5)).Bytes()...)
+	data = append(data, common.Hash{0xcc}.Bytes()...)
+	require.Equal(t, crypto.Keccak256Hash(data), leaf.Hash())
+}
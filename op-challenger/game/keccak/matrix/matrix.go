@@ -2,27 +2,42 @@ package matrix
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"math/big"
 
+	"github.com/ethereum-optimism/optimism/op-challenger/game/keccak/merkle"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
+// ErrNoPrestate is returned when requesting the prestate of the first leaf, which is absorbed into the initial state.
+var ErrNoPrestate = errors.New("first leaf has no prestate")
+
 // StateMatrix implements a stateful keccak sponge with the ability to create state commitments after each permutation
 type StateMatrix struct {
 	s *state
+	// leaves records each leaf absorbed, with prestates holding the state matrix prior to absorbing the leaf.
+	leaves    []Leaf
+	prestates []StateSnapshot
+	// merkle is the tree of absorbed leaves, lazily updated when a proof is required.
+	merkle *merkle.BinaryMerkleTree
 }
 
+// StateSnapshot is a copy of the 25 lanes of the keccak state matrix.
+type StateSnapshot [25]uint64
+
 // LeafSize is the size in bytes required for leaf data.
+// It is the rate of the keccak256 sponge, the number of bytes absorbed by each permutation, and is the single
+// source of truth for the block size used by the state matrix, large preimage leaves and the uploaders.
 const LeafSize = 136
 
 var uint256Size = 32
 
 // NewStateMatrix creates a new state matrix initialized with the initial, zero keccak block.
 func NewStateMatrix() *StateMatrix {
-	return &StateMatrix{s: newLegacyKeccak256()}
+	return &StateMatrix{s: newLegacyKeccak256(), merkle: merkle.NewBinaryMerkleTree()}
 }
 
 // StateCommitment returns the state commitment for the current state matrix.
@@ -32,6 +47,13 @@ func (d *StateMatrix) StateCommitment() common.Hash {
 	return crypto.Keccak256Hash(buf)
 }
 
+// StateSnapshot returns a copy of the current state matrix.
+func (d *StateMatrix) StateSnapshot() StateSnapshot {
+	var snap StateSnapshot
+	copy(snap[:], d.s.a[:])
+	return snap
+}
+
 // PackState packs the state in to the solidity ABI encoding required for the state matrix
 func (d *StateMatrix) PackState() []byte {
 	buf := make([]byte, 0, len(d.s.a)*uint256Size)
@@ -71,10 +93,65 @@ func (d *StateMatrix) AbsorbLeaf(data []byte, final bool) {
 	if !final && len(data) != LeafSize {
 		panic("sha3: Incorrect leaf data length")
 	}
+	d.prestates = append(d.prestates, d.StateSnapshot())
 	_, _ = d.s.Write(data[:])
 	if final {
 		d.s.padAndPermute(d.s.dsbyte)
 	}
+	d.leaves = append(d.leaves, Leaf{
+		Input:           append([]byte(nil), data...),
+		Index:           big.NewInt(int64(len(d.leaves))),
+		StateCommitment: d.StateCommitment(),
+	})
+}
+
+// Leaves returns the leaves absorbed so far, in the order they were absorbed.
+// Each leaf's Index is its position in the sequence of absorbed leaves.
+func (d *StateMatrix) Leaves() []Leaf {
+	return append([]Leaf(nil), d.leaves...)
+}
+
+// PrestateWithProof returns the leaf absorbed immediately before leafIdx and its merkle proof against the tree of
+// absorbed leaves. This is the prestate leaf required to challenge the leaf at leafIdx.
+// Returns [ErrNoPrestate] for the first leaf as it is absorbed into the initial, empty state matrix.
+func (d *StateMatrix) PrestateWithProof(leafIdx uint64) (Leaf, merkle.Proof, error) {
+	if leafIdx == 0 {
+		return Leaf{}, merkle.Proof{}, ErrNoPrestate
+	}
+	if leafIdx > uint64(len(d.leaves)) {
+		return Leaf{}, merkle.Proof{}, fmt.Errorf("%w: %v", merkle.ErrIndexOutOfBounds, leafIdx)
+	}
+	return d.leafWithProof(leafIdx - 1)
+}
+
+// PrestateMatrix returns the state matrix prior to absorbing the leaf at leafIdx.
+// The state commitment of the matrix matches the prestate leaf returned by PrestateWithProof.
+func (d *StateMatrix) PrestateMatrix(leafIdx uint64) (StateSnapshot, error) {
+	if leafIdx >= uint64(len(d.prestates)) {
+		return StateSnapshot{}, fmt.Errorf("%w: %v", merkle.ErrIndexOutOfBounds, leafIdx)
+	}
+	return d.prestates[leafIdx], nil
+}
+
+// PoststateWithProof returns the leaf at leafIdx and its merkle proof against the tree of absorbed leaves.
+func (d *StateMatrix) PoststateWithProof(leafIdx uint64) (Leaf, merkle.Proof, error) {
+	return d.leafWithProof(leafIdx)
+}
+
+func (d *StateMatrix) leafWithProof(leafIdx uint64) (Leaf, merkle.Proof, error) {
+	if leafIdx >= uint64(len(d.leaves)) {
+		return Leaf{}, merkle.Proof{}, fmt.Errorf("%w: %v", merkle.ErrIndexOutOfBounds, leafIdx)
+	}
+	for i := d.merkle.LeafCount(); i < uint64(len(d.leaves)); i++ {
+		if err := d.merkle.AddLeaf(d.leaves[i].Hash()); err != nil {
+			return Leaf{}, merkle.Proof{}, fmt.Errorf("failed to add leaf %v to merkle tree: %w", i, err)
+		}
+	}
+	proof, err := d.merkle.ProofAtIndex(leafIdx)
+	if err != nil {
+		return Leaf{}, merkle.Proof{}, fmt.Errorf("failed to create proof for leaf %v: %w", leafIdx, err)
+	}
+	return d.leaves[leafIdx], proof, nil
 }
 
 // Hash finalizes the keccak permutation and returns the final hash.
@@ -7,8 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"testing"
 
+	"github.com/ethereum-optimism/optimism/op-challenger/game/keccak/merkle"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
@@ -118,3 +120,110 @@ func FuzzKeccak(f *testing.F) {
 		require.Equal(t, expected, actual)
 	})
 }
+
+func TestStateSnapshot(t *testing.T) {
+	s := NewStateMatrix()
+	s.AbsorbLeaf(make([]byte, LeafSize), false)
+	snapshot := s.StateSnapshot()
+	require.Equal(t, s.s.a, [25]uint64(snapshot))
+
+	// Snapshot must be a copy so absorbing more data does not modify it.
+	s.AbsorbLeaf([]byte{1, 2, 3}, true)
+	require.NotEqual(t, s.s.a, [25]uint64(snapshot))
+}
+
+func TestStateProofs(t *testing.T) {
+	for _, size := range []int{0, 1, LeafSize - 1, LeafSize, LeafSize * 3, LeafSize*5 + 12} {
+		size := size
+		t.Run(fmt.Sprintf("Size-%v", size), func(t *testing.T) {
+			data := make([]byte, size)
+			for i := range data {
+				data[i] = byte(i)
+			}
+			s := NewStateMatrix()
+			commitments := []common.Hash{s.StateCommitment()}
+			in := bytes.NewReader(data)
+			for {
+				err := s.AbsorbNextLeaf(in)
+				commitments = append(commitments, s.StateCommitment())
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+			}
+			leafCount := uint64(size/LeafSize + 1)
+
+			tree := merkle.NewBinaryMerkleTree()
+			for i := uint64(0); i < leafCount; i++ {
+				leaf, _, err := s.PoststateWithProof(i)
+				require.NoError(t, err)
+				require.NoError(t, tree.AddLeaf(leaf.Hash()))
+			}
+			root := tree.RootHash()
+
+			for i := uint64(0); i < leafCount; i++ {
+				post, proof, err := s.PoststateWithProof(i)
+				require.NoError(t, err)
+				require.Equal(t, big.NewInt(int64(i)), post.Index)
+				require.Equal(t, commitments[i+1], post.StateCommitment)
+				require.True(t, merkle.Verify(root, i, post.Hash(), proof), "poststate proof for leaf %v should be valid", i)
+
+				prestateMatrix, err := s.PrestateMatrix(i)
+				require.NoError(t, err)
+				require.Equal(t, commitments[i], crypto.Keccak256Hash(packSnapshot(prestateMatrix)))
+
+				pre, proof, err := s.PrestateWithProof(i)
+				if i == 0 {
+					require.ErrorIs(t, err, ErrNoPrestate)
+					continue
+				}
+				require.NoError(t, err)
+				require.Equal(t, big.NewInt(int64(i-1)), pre.Index)
+				require.Equal(t, commitments[i], pre.StateCommitment)
+				require.True(t, merkle.Verify(root, i-1, pre.Hash(), proof), "prestate proof for leaf %v should be valid", i)
+			}
+
+			_, _, err := s.PoststateWithProof(leafCount)
+			require.ErrorIs(t, err, merkle.ErrIndexOutOfBounds)
+			_, _, err = s.PrestateWithProof(leafCount + 1)
+			require.ErrorIs(t, err, merkle.ErrIndexOutOfBounds)
+			_, err = s.PrestateMatrix(leafCount)
+			require.ErrorIs(t, err, merkle.ErrIndexOutOfBounds)
+		})
+	}
+}
+
+func packSnapshot(snapshot StateSnapshot) []byte {
+	s := NewStateMatrix()
+	copy(s.s.a[:], snapshot[:])
+	return s.PackState()
+}
+
+func TestLeafSizeIsKeccakRate(t *testing.T) {
+	require.Equal(t, 136, LeafSize)
+	require.Equal(t, LeafSize, NewStateMatrix().s.rate)
+}
+
+func TestLeaves(t *testing.T) {
+	s := NewStateMatrix()
+	require.Empty(t, s.Leaves())
+
+	data := make([]byte, LeafSize*2+10)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	s.AbsorbLeaf(data[:LeafSize], false)
+	first := s.StateCommitment()
+	s.AbsorbLeaf(data[LeafSize:LeafSize*2], false)
+	s.AbsorbLeaf(data[LeafSize*2:], true)
+
+	leaves := s.Leaves()
+	require.Len(t, leaves, 3)
+	for i, leaf := range leaves {
+		require.Equal(t, big.NewInt(int64(i)), leaf.Index)
+	}
+	require.Equal(t, data[:LeafSize], leaves[0].Input)
+	require.Equal(t, first, leaves[0].StateCommitment)
+	require.Equal(t, data[LeafSize*2:], leaves[2].Input)
+	require.Equal(t, s.StateCommitment(), leaves[2].StateCommitment)
+}
@@ -0,0 +1,119 @@
+package merkle
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// BinaryMerkleTreeDepth is the depth of the merkle tree used by the PreimageOracle contract (KECCAK_TREE_DEPTH).
+const BinaryMerkleTreeDepth = 16
+
+// MaxLeafCount is the maximum number of leaves that can be added to the tree (MAX_LEAF_COUNT).
+const MaxLeafCount = 1<<BinaryMerkleTreeDepth - 1
+
+var (
+	ErrTreeFull         = errors.New("merkle tree is full")
+	ErrIndexOutOfBounds = errors.New("leaf index out of bounds")
+)
+
+// zeroHashes are the roots of empty subtrees at each height in the tree.
+var zeroHashes = func() [BinaryMerkleTreeDepth]common.Hash {
+	var hashes [BinaryMerkleTreeDepth]common.Hash
+	for height := 0; height < BinaryMerkleTreeDepth-1; height++ {
+		hashes[height+1] = hashTwo(hashes[height], hashes[height])
+	}
+	return hashes
+}()
+
+// Proof is the sibling nodes along the path from a leaf to the root, starting at the leaf.
+type Proof [BinaryMerkleTreeDepth]common.Hash
+
+// BinaryMerkleTree is a fixed depth binary merkle tree using keccak256, matching the incremental
+// merkle tree the PreimageOracle contract builds from the leaves of a large preimage proposal.
+// Unset leaves are treated as zero.
+type BinaryMerkleTree struct {
+	leaves []common.Hash
+}
+
+func NewBinaryMerkleTree() *BinaryMerkleTree {
+	return &BinaryMerkleTree{}
+}
+
+// AddLeaf appends the leaf hash to the tree.
+func (m *BinaryMerkleTree) AddLeaf(leaf common.Hash) error {
+	if len(m.leaves) >= MaxLeafCount {
+		return ErrTreeFull
+	}
+	m.leaves = append(m.leaves, leaf)
+	return nil
+}
+
+// LeafCount returns the number of leaves added to the tree.
+func (m *BinaryMerkleTree) LeafCount() uint64 {
+	return uint64(len(m.leaves))
+}
+
+// RootHash returns the current root of the tree.
+func (m *BinaryMerkleTree) RootHash() common.Hash {
+	nodes := m.leaves
+	for height := 0; height < BinaryMerkleTreeDepth; height++ {
+		nodes = parentLayer(nodes, height)
+	}
+	if len(nodes) == 0 {
+		return hashTwo(zeroHashes[BinaryMerkleTreeDepth-1], zeroHashes[BinaryMerkleTreeDepth-1])
+	}
+	return nodes[0]
+}
+
+// ProofAtIndex returns the merkle proof for the leaf at the specified index.
+func (m *BinaryMerkleTree) ProofAtIndex(index uint64) (Proof, error) {
+	if index >= uint64(len(m.leaves)) {
+		return Proof{}, ErrIndexOutOfBounds
+	}
+	var proof Proof
+	nodes := m.leaves
+	for height := 0; height < BinaryMerkleTreeDepth; height++ {
+		sibling := index ^ 1
+		if sibling < uint64(len(nodes)) {
+			proof[height] = nodes[sibling]
+		} else {
+			proof[height] = zeroHashes[height]
+		}
+		nodes = parentLayer(nodes, height)
+		index >>= 1
+	}
+	return proof, nil
+}
+
+// Verify checks that leaf is at index in the tree with the specified root.
+func Verify(root common.Hash, index uint64, leaf common.Hash, proof Proof) bool {
+	value := leaf
+	for height := 0; height < BinaryMerkleTreeDepth; height++ {
+		if (index>>height)&1 == 1 {
+			value = hashTwo(proof[height], value)
+		} else {
+			value = hashTwo(value, proof[height])
+		}
+	}
+	return value == root
+}
+
+// parentLayer hashes pairs of nodes at height to produce the nodes at the next height.
+func parentLayer(nodes []common.Hash, height int) []common.Hash {
+	parents := make([]common.Hash, (len(nodes)+1)/2)
+	for i := range parents {
+		left := nodes[2*i]
+		right := zeroHashes[height]
+		if 2*i+1 < len(nodes) {
+			right = nodes[2*i+1]
+		}
+		parents[i] = hashTwo(left, right)
+	}
+	return parents
+}
+
+func hashTwo(a, b common.Hash) common.Hash {
+	return crypto.Keccak256Hash(a[:], b[:])
+}
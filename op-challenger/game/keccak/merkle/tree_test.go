@@ -0,0 +1,90 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootHash(t *testing.T) {
+	for _, count := range []int{0, 1, 2, 3, 4, 5, 7, 8, 9, 100, 257} {
+		count := count
+		t.Run(fmt.Sprintf("Leaves-%v", count), func(t *testing.T) {
+			tree := NewBinaryMerkleTree()
+			contract := &incrementalTree{}
+			for i := 0; i < count; i++ {
+				leaf := crypto.Keccak256Hash([]byte{byte(i), byte(i >> 8)})
+				require.NoError(t, tree.AddLeaf(leaf))
+				contract.addLeaf(leaf)
+			}
+			require.Equal(t, uint64(count), tree.LeafCount())
+			require.Equal(t, contract.root(), tree.RootHash())
+		})
+	}
+}
+
+func TestProofAtIndex(t *testing.T) {
+	for _, count := range []int{1, 2, 3, 4, 5, 7, 8, 9, 100} {
+		count := count
+		t.Run(fmt.Sprintf("Leaves-%v", count), func(t *testing.T) {
+			tree := NewBinaryMerkleTree()
+			for i := 0; i < count; i++ {
+				require.NoError(t, tree.AddLeaf(crypto.Keccak256Hash([]byte{byte(i)})))
+			}
+			root := tree.RootHash()
+			for i := 0; i < count; i++ {
+				leaf := crypto.Keccak256Hash([]byte{byte(i)})
+				proof, err := tree.ProofAtIndex(uint64(i))
+				require.NoError(t, err)
+				require.True(t, Verify(root, uint64(i), leaf, proof), "proof for leaf %v should be valid", i)
+				require.False(t, Verify(root, uint64(i), common.Hash{0xff}, proof), "proof for incorrect leaf %v should be invalid", i)
+			}
+		})
+	}
+}
+
+func TestProofAtIndexOutOfBounds(t *testing.T) {
+	tree := NewBinaryMerkleTree()
+	_, err := tree.ProofAtIndex(0)
+	require.ErrorIs(t, err, ErrIndexOutOfBounds)
+
+	require.NoError(t, tree.AddLeaf(common.Hash{0xaa}))
+	_, err = tree.ProofAtIndex(1)
+	require.ErrorIs(t, err, ErrIndexOutOfBounds)
+}
+
+// incrementalTree mirrors the incremental merkle tree implemented by the PreimageOracle contract.
+type incrementalTree struct {
+	branch [BinaryMerkleTreeDepth]common.Hash
+	size   uint64
+}
+
+func (c *incrementalTree) addLeaf(node common.Hash) {
+	c.size++
+	size := c.size
+	for height := 0; height < BinaryMerkleTreeDepth; height++ {
+		if size&1 == 1 {
+			c.branch[height] = node
+			return
+		}
+		node = hashTwo(c.branch[height], node)
+		size >>= 1
+	}
+}
+
+func (c *incrementalTree) root() common.Hash {
+	var root common.Hash
+	size := c.size
+	for height := 0; height < BinaryMerkleTreeDepth; height++ {
+		if size&1 == 1 {
+			root = hashTwo(c.branch[height], root)
+		} else {
+			root = hashTwo(root, zeroHashes[height])
+		}
+		size >>= 1
+	}
+	return root
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-challenger/game/types"
 	"github.com/ethereum-optimism/optimism/op-service/clock"
 	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum-optimism/optimism/op-service/sources/batching"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -19,6 +20,10 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 )
 
+// activePreimagesInterval is the minimum time between recording the number of active large preimage proposals.
+// Counting them reads the metadata of every proposal ever made so is too expensive to do for every L1 head.
+const activePreimagesInterval = 5 * time.Minute
+
 type blockNumberFetcher func(ctx context.Context) (uint64, error)
 
 // gameSource loads information about the games available to play
@@ -30,17 +35,31 @@ type gameScheduler interface {
 	Schedule([]types.GameMetadata, uint64) error
 }
 
+// oracleSource provides the large preimage oracles used by the supported game types
+type oracleSource interface {
+	Oracles() []types.LargePreimageOracle
+}
+
+type ActivePreimageMetricer interface {
+	RecordActivePreimages(countered, uncountered int)
+}
+
 type gameMonitor struct {
 	logger           log.Logger
+	metrics          ActivePreimageMetricer
 	clock            clock.Clock
 	source           gameSource
 	scheduler        gameScheduler
+	oracles          oracleSource
 	gameWindow       time.Duration
 	fetchBlockNumber blockNumberFetcher
 	allowedGames     []common.Address
 	l1HeadsSub       ethereum.Subscription
 	l1Source         *headSource
 	runState         sync.Mutex
+
+	// lastActivePreimages is the time the active large preimage proposals were last recorded.
+	lastActivePreimages time.Time
 }
 
 type MinimalSubscriber interface {
@@ -57,9 +76,11 @@ func (s *headSource) SubscribeNewHead(ctx context.Context, ch chan<- *ethTypes.H
 
 func newGameMonitor(
 	logger log.Logger,
+	m ActivePreimageMetricer,
 	cl clock.Clock,
 	source gameSource,
 	scheduler gameScheduler,
+	oracles oracleSource,
 	gameWindow time.Duration,
 	fetchBlockNumber blockNumberFetcher,
 	allowedGames []common.Address,
@@ -67,8 +88,10 @@ func newGameMonitor(
 ) *gameMonitor {
 	return &gameMonitor{
 		logger:           logger,
+		metrics:          m,
 		clock:            cl,
 		scheduler:        scheduler,
+		oracles:          oracles,
 		source:           source,
 		gameWindow:       gameWindow,
 		fetchBlockNumber: fetchBlockNumber,
@@ -122,10 +145,32 @@ func (m *gameMonitor) progressGames(ctx context.Context, blockHash common.Hash,
 	return nil
 }
 
+// recordActivePreimages records the number of active large preimage proposals across all oracles at blockHash.
+func (m *gameMonitor) recordActivePreimages(ctx context.Context, blockHash common.Hash) error {
+	var total types.ActivePreimageCounts
+	for _, oracle := range m.oracles.Oracles() {
+		counts, err := oracle.CountActivePreimages(ctx, batching.BlockByHash(blockHash))
+		if err != nil {
+			return fmt.Errorf("failed to count active preimages in oracle %v: %w", oracle.Addr(), err)
+		}
+		total.Countered += counts.Countered
+		total.Uncountered += counts.Uncountered
+	}
+	m.metrics.RecordActivePreimages(total.Countered, total.Uncountered)
+	return nil
+}
+
 func (m *gameMonitor) onNewL1Head(ctx context.Context, sig eth.L1BlockRef) {
 	if err := m.progressGames(ctx, sig.Hash, sig.Number); err != nil {
 		m.logger.Error("Failed to progress games", "err", err)
 	}
+	if now := m.clock.Now(); now.Sub(m.lastActivePreimages) >= activePreimagesInterval {
+		if err := m.recordActivePreimages(ctx, sig.Hash); err != nil {
+			m.logger.Error("Failed to record active preimages", "err", err)
+		} else {
+			m.lastActivePreimages = now
+		}
+	}
 }
 
 func (m *gameMonitor) resubscribeFunction() event.ResubscribeErrFunc {
@@ -2,6 +2,7 @@ package game
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"sync"
@@ -9,6 +10,8 @@ import (
 	"time"
 
 	"github.com/ethereum-optimism/optimism/op-challenger/game/types"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum-optimism/optimism/op-service/sources/batching"
 	"github.com/ethereum-optimism/optimism/op-service/testlog"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -166,6 +169,58 @@ func TestMonitorOnlyScheduleSpecifiedGame(t *testing.T) {
 	require.Equal(t, []common.Address{addr2}, sched.Scheduled()[0])
 }
 
+func TestMonitorRecordActivePreimages(t *testing.T) {
+	monitor, _, _, _ := setupMonitorTest(t, []common.Address{})
+	m := &stubActivePreimageMetrics{}
+	monitor.metrics = m
+	oracleA := &stubPreimageOracle{addr: common.Address{0xaa}, counts: types.ActivePreimageCounts{Countered: 1, Uncountered: 2}}
+	oracleB := &stubPreimageOracle{addr: common.Address{0xbb}, counts: types.ActivePreimageCounts{Countered: 3, Uncountered: 4}}
+	monitor.oracles = &stubOracleSource{oracles: []types.LargePreimageOracle{oracleA, oracleB}}
+
+	blockHash := common.Hash{0x01}
+	require.NoError(t, monitor.recordActivePreimages(context.Background(), blockHash))
+	require.Equal(t, 4, m.countered)
+	require.Equal(t, 6, m.uncountered)
+	require.Equal(t, batching.BlockByHash(blockHash), oracleA.block)
+	require.Equal(t, batching.BlockByHash(blockHash), oracleB.block)
+
+	oracleB.err = errors.New("boom")
+	require.ErrorIs(t, monitor.recordActivePreimages(context.Background(), blockHash), oracleB.err)
+	// The metric isn't updated with a partial count.
+	require.Equal(t, 4, m.countered)
+	require.Equal(t, 6, m.uncountered)
+}
+
+func TestMonitorRecordActivePreimagesInterval(t *testing.T) {
+	monitor, _, _, _ := setupMonitorTest(t, []common.Address{})
+	cl := clock.NewDeterministicClock(time.Unix(10_000, 0))
+	monitor.clock = cl
+	oracle := &stubPreimageOracle{addr: common.Address{0xaa}}
+	monitor.oracles = &stubOracleSource{oracles: []types.LargePreimageOracle{oracle}}
+
+	monitor.onNewL1Head(context.Background(), eth.L1BlockRef{Hash: common.Hash{0x01}})
+	require.Equal(t, 1, oracle.calls)
+
+	// Not recorded again until the interval has passed.
+	cl.AdvanceTime(activePreimagesInterval - time.Second)
+	monitor.onNewL1Head(context.Background(), eth.L1BlockRef{Hash: common.Hash{0x02}})
+	require.Equal(t, 1, oracle.calls)
+
+	cl.AdvanceTime(time.Second)
+	monitor.onNewL1Head(context.Background(), eth.L1BlockRef{Hash: common.Hash{0x03}})
+	require.Equal(t, 2, oracle.calls)
+	require.Equal(t, batching.BlockByHash(common.Hash{0x03}), oracle.block)
+
+	// Failed attempts are retried on the next L1 head.
+	oracle.err = errors.New("boom")
+	cl.AdvanceTime(activePreimagesInterval)
+	monitor.onNewL1Head(context.Background(), eth.L1BlockRef{Hash: common.Hash{0x04}})
+	require.Equal(t, 3, oracle.calls)
+	oracle.err = nil
+	monitor.onNewL1Head(context.Background(), eth.L1BlockRef{Hash: common.Hash{0x05}})
+	require.Equal(t, 4, oracle.calls)
+}
+
 func newFDG(proxy common.Address, timestamp uint64) types.GameMetadata {
 	return types.GameMetadata{
 		Proxy:     proxy,
@@ -188,9 +243,11 @@ func setupMonitorTest(
 	mockHeadSource := &mockNewHeadSource{}
 	monitor := newGameMonitor(
 		logger,
+		&stubActivePreimageMetrics{},
 		clock.SystemClock,
 		source,
 		sched,
+		&stubOracleSource{},
 		time.Duration(0),
 		fetchBlockNum,
 		allowedGames,
@@ -261,6 +318,42 @@ func (s *stubGameSource) FetchAllGamesAtBlock(
 	return s.games, nil
 }
 
+type stubOracleSource struct {
+	oracles []types.LargePreimageOracle
+}
+
+func (s *stubOracleSource) Oracles() []types.LargePreimageOracle {
+	return s.oracles
+}
+
+type stubPreimageOracle struct {
+	addr   common.Address
+	counts types.ActivePreimageCounts
+	err    error
+	block  batching.Block
+	calls  int
+}
+
+func (s *stubPreimageOracle) Addr() common.Address {
+	return s.addr
+}
+
+func (s *stubPreimageOracle) CountActivePreimages(_ context.Context, block batching.Block) (types.ActivePreimageCounts, error) {
+	s.calls++
+	s.block = block
+	return s.counts, s.err
+}
+
+type stubActivePreimageMetrics struct {
+	countered   int
+	uncountered int
+}
+
+func (s *stubActivePreimageMetrics) RecordActivePreimages(countered, uncountered int) {
+	s.countered = countered
+	s.uncountered = uncountered
+}
+
 type stubScheduler struct {
 	sync.Mutex
 	scheduled [][]common.Address
@@ -1,11 +1,13 @@
 package registry
 
 import (
+	"context"
 	"testing"
 
 	"github.com/ethereum-optimism/optimism/op-challenger/game/scheduler"
 	"github.com/ethereum-optimism/optimism/op-challenger/game/scheduler/test"
 	"github.com/ethereum-optimism/optimism/op-challenger/game/types"
+	"github.com/ethereum-optimism/optimism/op-service/sources/batching"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
@@ -61,3 +63,7 @@ type stubPreimageOracle common.Address
 func (s stubPreimageOracle) Addr() common.Address {
 	return common.Address(s)
 }
+
+func (s stubPreimageOracle) CountActivePreimages(_ context.Context, _ batching.Block) (types.ActivePreimageCounts, error) {
+	return types.ActivePreimageCounts{}, nil
+}
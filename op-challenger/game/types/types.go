@@ -1,8 +1,10 @@
 package types
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/ethereum-optimism/optimism/op-service/sources/batching"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -42,6 +44,13 @@ type GameMetadata struct {
 	Proxy     common.Address
 }
 
+// ActivePreimageCounts is the number of large preimage proposals in an oracle, split by whether they have been countered.
+type ActivePreimageCounts struct {
+	Countered   int
+	Uncountered int
+}
+
 type LargePreimageOracle interface {
 	Addr() common.Address
+	CountActivePreimages(ctx context.Context, block batching.Block) (ActivePreimageCounts, error)
 }
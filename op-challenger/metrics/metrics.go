@@ -39,6 +39,11 @@ type Metricer interface {
 	RecordGameUpdateScheduled()
 	RecordGameUpdateCompleted()
 
+	RecordLargePreimageUploadInit()
+	RecordLargePreimageLeavesUploaded(count int)
+	RecordLargePreimageUploadComplete()
+	RecordActivePreimages(countered, uncountered int)
+
 	IncActiveExecutors()
 	DecActiveExecutors()
 	IncIdleExecutors()
@@ -71,6 +76,10 @@ type Metrics struct {
 
 	trackedGames  prometheus.GaugeVec
 	inflightGames prometheus.Gauge
+
+	largePreimageUploads        prometheus.CounterVec
+	largePreimageLeavesUploaded prometheus.Counter
+	activePreimages             prometheus.GaugeVec
 }
 
 func (m *Metrics) Registry() *prometheus.Registry {
@@ -146,6 +155,25 @@ func NewMetrics() *Metrics {
 			Name:      "inflight_games",
 			Help:      "Number of games being tracked by the challenger",
 		}),
+		largePreimageUploads: *factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "large_preimage_uploads",
+			Help:      "Number of large preimage uploads initialized and completed by the challenger",
+		}, []string{
+			"status",
+		}),
+		largePreimageLeavesUploaded: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "large_preimage_leaves_uploaded",
+			Help:      "Number of large preimage leaves added to the preimage oracle by the challenger",
+		}),
+		activePreimages: *factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "active_preimages",
+			Help:      "Number of large preimage proposals in the preimage oracle",
+		}, []string{
+			"status",
+		}),
 	}
 }
 
@@ -222,3 +250,20 @@ func (m *Metrics) RecordGameUpdateScheduled() {
 func (m *Metrics) RecordGameUpdateCompleted() {
 	m.inflightGames.Sub(1)
 }
+
+func (m *Metrics) RecordLargePreimageUploadInit() {
+	m.largePreimageUploads.WithLabelValues("init").Inc()
+}
+
+func (m *Metrics) RecordLargePreimageLeavesUploaded(count int) {
+	m.largePreimageLeavesUploaded.Add(float64(count))
+}
+
+func (m *Metrics) RecordLargePreimageUploadComplete() {
+	m.largePreimageUploads.WithLabelValues("complete").Inc()
+}
+
+func (m *Metrics) RecordActivePreimages(countered, uncountered int) {
+	m.activePreimages.WithLabelValues("countered").Set(float64(countered))
+	m.activePreimages.WithLabelValues("uncountered").Set(float64(uncountered))
+}
@@ -35,6 +35,12 @@ func (*NoopMetricsImpl) RecordGamesStatus(inProgress, defenderWon, challengerWon
 func (*NoopMetricsImpl) RecordGameUpdateScheduled() {}
 func (*NoopMetricsImpl) RecordGameUpdateCompleted() {}
 
+func (*NoopMetricsImpl) RecordLargePreimageUploadInit()          {}
+func (*NoopMetricsImpl) RecordLargePreimageLeavesUploaded(_ int) {}
+func (*NoopMetricsImpl) RecordLargePreimageUploadComplete()      {}
+
+func (*NoopMetricsImpl) RecordActivePreimages(_, _ int) {}
+
 func (*NoopMetricsImpl) IncActiveExecutors() {}
 func (*NoopMetricsImpl) DecActiveExecutors() {}
 func (*NoopMetricsImpl) IncIdleExecutors()   {}
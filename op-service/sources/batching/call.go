@@ -1,6 +1,7 @@
 package batching
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -23,10 +24,32 @@ func NewBoundContract(abi *abi.ABI, addr common.Address) *BoundContract {
 	}
 }
 
+var (
+	ErrUnknownMethod = errors.New("unknown method")
+	ErrInvalidCall   = errors.New("invalid call")
+)
+
 func (b *BoundContract) Call(method string, args ...interface{}) *ContractCall {
 	return NewContractCall(b.abi, b.addr, method, args...)
 }
 
+// DecodeCall decodes the calldata of a transaction or call to the contract, returning the name of the
+// method called and its arguments.
+func (b *BoundContract) DecodeCall(data []byte) (string, *CallResult, error) {
+	if len(data) < 4 {
+		return "", nil, ErrUnknownMethod
+	}
+	method, err := b.abi.MethodById(data[:4])
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrUnknownMethod, err)
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrInvalidCall, err)
+	}
+	return method.Name, &CallResult{out: args}, nil
+}
+
 type ContractCall struct {
 	Abi    *abi.ABI
 	Addr   common.Address
@@ -102,6 +125,11 @@ type CallResult struct {
 	out []interface{}
 }
 
+// Len returns the number of values returned by the call.
+func (c *CallResult) Len() int {
+	return len(c.out)
+}
+
 func (c *CallResult) GetUint8(i int) uint8 {
 	return *abi.ConvertType(c.out[i], new(uint8)).(*uint8)
 }
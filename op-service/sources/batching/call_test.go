@@ -100,6 +100,42 @@ func TestContractCall_UnpackInvalid(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestBoundContract_DecodeCall(t *testing.T) {
+	testAbi, err := bindings.ERC20MetaData.GetAbi()
+	require.NoError(t, err)
+	contract := NewBoundContract(testAbi, common.Address{0xbd})
+
+	t.Run("Valid", func(t *testing.T) {
+		spender := common.Address{0xcc}
+		amount := big.NewInt(1234444)
+		data, err := contract.Call("approve", spender, amount).Pack()
+		require.NoError(t, err)
+
+		method, args, err := contract.DecodeCall(data)
+		require.NoError(t, err)
+		require.Equal(t, "approve", method)
+		require.Equal(t, spender, args.GetAddress(0))
+		require.Equal(t, amount, args.GetBigInt(1))
+	})
+
+	t.Run("TooShort", func(t *testing.T) {
+		_, _, err := contract.DecodeCall([]byte{1, 2, 3})
+		require.ErrorIs(t, err, ErrUnknownMethod)
+	})
+
+	t.Run("UnknownMethod", func(t *testing.T) {
+		_, _, err := contract.DecodeCall([]byte{1, 2, 3, 4, 5})
+		require.ErrorIs(t, err, ErrUnknownMethod)
+	})
+
+	t.Run("InvalidArgs", func(t *testing.T) {
+		data, err := contract.Call("approve", common.Address{0xcc}, big.NewInt(1234444)).Pack()
+		require.NoError(t, err)
+		_, _, err = contract.DecodeCall(data[:20])
+		require.ErrorIs(t, err, ErrInvalidCall)
+	})
+}
+
 func TestCallResult_GetValues(t *testing.T) {
 	tests := []struct {
 		name     string